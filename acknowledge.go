@@ -0,0 +1,61 @@
+package mesosutil
+
+import (
+	"context"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Acknowledge the status update of a task. Mesos resend the update until it
+// is acknowledged. Updates without uuid do not need an acknowledgement.
+func (c *Client) Acknowledge(status mesosproto.TaskStatus) error {
+	return c.AcknowledgeContext(context.Background(), status)
+}
+
+// AcknowledgeContext acknowledge the status update of a task until the context is done
+func (c *Client) AcknowledgeContext(ctx context.Context, status mesosproto.TaskStatus) error {
+	if status.UUID == nil || status.AgentID == nil {
+		return nil
+	}
+
+	logrus.WithField("func", "Acknowledge").Debug("Acknowledge status of task ", status.TaskID.Value)
+	return c.CallContext(ctx, &mesosproto.Call{
+		Type: mesosproto.Call_ACKNOWLEDGE,
+		Acknowledge: &mesosproto.Call_Acknowledge{
+			AgentID: *status.AgentID,
+			TaskID:  status.TaskID,
+			UUID:    status.UUID,
+		},
+	})
+}
+
+// AcknowledgeMiddleware acknowledge every UPDATE event after the handler
+// returned successfully. If the handler fail, mesos will resend the update.
+func (c *Client) AcknowledgeMiddleware(next EventHandler) EventHandler {
+	return func(event *mesosproto.Event) error {
+		err := next(event)
+		if err != nil || event.Type != mesosproto.Event_UPDATE || event.Update == nil {
+			return err
+		}
+		return c.Acknowledge(event.Update.Status)
+	}
+}
+
+// Acknowledge the status update of a task with the default client
+func Acknowledge(status mesosproto.TaskStatus) error {
+	return defaultClient.Acknowledge(status)
+}
+
+// AcknowledgeContext acknowledge the status update of a task with the default client
+func AcknowledgeContext(ctx context.Context, status mesosproto.TaskStatus) error {
+	return defaultClient.AcknowledgeContext(ctx, status)
+}
+
+// AcknowledgeMiddleware acknowledge every UPDATE event with the default client
+func AcknowledgeMiddleware(next EventHandler) EventHandler {
+	return func(event *mesosproto.Event) error {
+		return defaultClient.AcknowledgeMiddleware(next)(event)
+	}
+}
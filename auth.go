@@ -0,0 +1,92 @@
+package mesosutil
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Authenticator add the credentials to every request at the mesos master
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// BasicAuth authenticate with http basic auth
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Authenticate set the basic auth header
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// BearerToken authenticate with a static bearer token
+type BearerToken string
+
+// Authenticate set the bearer token header
+func (t BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// TokenSource get a new token and the time until the token is valid
+type TokenSource func(ctx context.Context) (string, time.Time, error)
+
+// RefreshingToken authenticate with a bearer token of the token source.
+// The token will be fetched again shortly before it expire.
+type RefreshingToken struct {
+	Source TokenSource
+	// Leeway is how long before the expiry the token will be refreshed
+	Leeway time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewRefreshingToken create a refreshing token authenticator of the source
+func NewRefreshingToken(source TokenSource) *RefreshingToken {
+	return &RefreshingToken{
+		Source: source,
+		Leeway: 1 * time.Minute,
+	}
+}
+
+// Authenticate set the bearer token header with a valid token
+func (t *RefreshingToken) Authenticate(req *http.Request) error {
+	token, err := t.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token return the cached token, or fetch a new one if it expire soon
+func (t *RefreshingToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Now().Add(t.Leeway).Before(t.expiry) {
+		return t.token, nil
+	}
+
+	token, expiry, err := t.Source(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.expiry = expiry
+	return t.token, nil
+}
+
+// Invalidate drop the cached token, the next request will fetch a new one
+func (t *RefreshingToken) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
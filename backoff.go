@@ -0,0 +1,46 @@
+package mesosutil
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff describe an exponential backoff
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	Factor  float64
+	// Jitter randomize the wait time by up to this fraction (0 to 1)
+	Jitter float64
+}
+
+// DefaultBackoff is the backoff used by new clients
+var DefaultBackoff = Backoff{
+	Initial: 1 * time.Second,
+	Max:     60 * time.Second,
+	Factor:  2,
+	Jitter:  0.2,
+}
+
+// Duration return how long to wait before the given attempt. The first
+// attempt is 0 and does not wait at all.
+func (b Backoff) Duration(attempt int) time.Duration {
+	if attempt <= 0 {
+		return 0
+	}
+	wait := float64(b.Initial)
+	for i := 1; i < attempt; i++ {
+		wait *= b.Factor
+		if b.Max > 0 && wait >= float64(b.Max) {
+			break
+		}
+	}
+	if b.Max > 0 && wait > float64(b.Max) {
+		wait = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		// #nosec G404
+		wait += wait * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(wait)
+}
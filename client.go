@@ -0,0 +1,237 @@
+package mesosutil
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Client talks to one mesos master as one framework. Every client owns its
+// own http transport, stream id and framework id, so one process can run
+// several frameworks side by side.
+type Client struct {
+	config *FrameworkConfig
+	client *http.Client
+	// err is returned by every request if the client could not be set up
+	err error
+
+	mu           sync.Mutex
+	leader       string
+	retries      uint64
+	reservations map[string]Reservation
+
+	// Backoff between the attempts to resubscribe
+	Backoff Backoff
+	// Codec of the calls and the event stream
+	Codec Codec
+	// Timeout of every request except the subscription stream
+	Timeout time.Duration
+	// Auth add the credentials to every request
+	Auth Authenticator
+	// Retry policy of the calls
+	Retry RetryPolicy
+	// Refuse policy of declined offers
+	Refuse *RefusePolicy
+	// Operations track the offer operations. If it is set, every accepted
+	// operation except LAUNCH get an id to receive its status updates.
+	Operations *OperationTracker
+}
+
+// defaultClient is used by the package level functions
+var defaultClient *Client
+
+// DefaultTimeout is the request timeout of new clients
+var DefaultTimeout = 30 * time.Second
+
+// NewClient create a new scheduler client for the given framework config.
+// If the tls config is invalid, every request of the client will fail. Use
+// NewClientE to get the error at construction time.
+func NewClient(cfg *FrameworkConfig) *Client {
+	c, err := NewClientE(cfg)
+	if err != nil {
+		logrus.WithField("func", "NewClient").Error("Invalid tls config: ", err.Error())
+	}
+	return c
+}
+
+// NewClientE create a new scheduler client for the given framework config
+// and return the error of an invalid tls config. The client is returned
+// anyway, but every request of it will fail with that error.
+func NewClientE(cfg *FrameworkConfig) (*Client, error) {
+	tlsConfig, err := TLSConfig(cfg)
+
+	var auth Authenticator
+	if cfg.Username != "" {
+		auth = BasicAuth{Username: cfg.Username, Password: cfg.Password}
+	}
+
+	return &Client{
+		config: cfg,
+		client: &http.Client{
+			// redirects of non leading masters are handled by do()
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+			Transport: newTransport(tlsConfig),
+		},
+		err:     err,
+		Backoff: DefaultBackoff,
+		Codec:   JSONCodec,
+		Timeout: DefaultTimeout,
+		Auth:    auth,
+		Retry:   DefaultRetryPolicy.Copy(),
+		Refuse:  NewRefusePolicy(5*time.Second, 5*time.Minute),
+	}, err
+}
+
+// Transport return the http transport of the client, to tune the
+// connection pool before the first request
+func (c *Client) Transport() *http.Transport {
+	return c.client.Transport.(*http.Transport)
+}
+
+// Config return the framework config of the client
+func (c *Client) Config() *FrameworkConfig {
+	return c.config
+}
+
+// StreamID return the Mesos-Stream-Id of the current subscription
+func (c *Client) StreamID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.MesosStreamID
+}
+
+// setStreamID remember the Mesos-Stream-Id of a new subscription
+func (c *Client) setStreamID(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.MesosStreamID = id
+}
+
+// FrameworkID return the framework id mesos gave the framework, or nil
+// before the first subscription
+func (c *Client) FrameworkID() *mesosproto.FrameworkID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.FrameworkInfo.ID
+}
+
+// setFrameworkID remember the framework id of the SUBSCRIBED event
+func (c *Client) setFrameworkID(id *mesosproto.FrameworkID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.FrameworkInfo.ID = id
+}
+
+// frameworkInfo return a copy of the framework info
+func (c *Client) frameworkInfo() mesosproto.FrameworkInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.FrameworkInfo
+}
+
+// url build the full url of the given path at the mesos master
+func (c *Client) url(path string) string {
+	protocol := "https"
+	if !c.config.MesosSSL {
+		protocol = "http"
+	}
+	return protocol + "://" + c.master() + path
+}
+
+// withTimeout limit the context to the request timeout of the client
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// SetConfig set the global config. It return the error of an invalid tls
+// config, the package level functions will fail with it.
+func SetConfig(cfg *FrameworkConfig) error {
+	var err error
+	defaultClient, err = NewClientE(cfg)
+	return err
+}
+
+// DefaultClient return the client used by the package level functions
+func DefaultClient() *Client {
+	return defaultClient
+}
+
+// Call will send messages to mesos
+func Call(message *mesosproto.Call) error {
+	return defaultClient.Call(message)
+}
+
+// CallContext will send messages to mesos until the context is done
+func CallContext(ctx context.Context, message *mesosproto.Call) error {
+	return defaultClient.CallContext(ctx, message)
+}
+
+// Revive will revive the mesos tasks to clean up
+func Revive() {
+	defaultClient.Revive()
+}
+
+// ReviveContext will revive the mesos tasks to clean up
+func ReviveContext(ctx context.Context) {
+	defaultClient.ReviveContext(ctx)
+}
+
+// SuppressFramework if all Tasks are running, suppress framework offers
+func SuppressFramework() {
+	defaultClient.SuppressFramework()
+}
+
+// SuppressFrameworkContext if all Tasks are running, suppress framework offers
+func SuppressFrameworkContext(ctx context.Context) {
+	defaultClient.SuppressFrameworkContext(ctx)
+}
+
+// Kill a Task with the given taskID
+func Kill(taskID string, agentID string) error {
+	return defaultClient.Kill(taskID, agentID)
+}
+
+// KillContext kill a Task with the given taskID until the context is done
+func KillContext(ctx context.Context, taskID string, agentID string) error {
+	return defaultClient.KillContext(ctx, taskID, agentID)
+}
+
+// GetOffer get out the offer for the mesos task
+func GetOffer(offers *mesosproto.Event_Offers, cmd Command) (mesosproto.Offer, []mesosproto.OfferID) {
+	return defaultClient.GetOffer(offers, cmd)
+}
+
+// GetOfferContext get out the offer for the mesos task
+func GetOfferContext(ctx context.Context, offers *mesosproto.Event_Offers, cmd Command) (mesosproto.Offer, []mesosproto.OfferID) {
+	return defaultClient.GetOfferContext(ctx, offers, cmd)
+}
+
+// GetAgentInfo get information about the agent
+func GetAgentInfo(agentID string) MesosSlaves {
+	return defaultClient.GetAgentInfo(agentID)
+}
+
+// GetAgentInfoContext get information about the agent until the context is done
+func GetAgentInfoContext(ctx context.Context, agentID string) MesosSlaves {
+	return defaultClient.GetAgentInfoContext(ctx, agentID)
+}
+
+// GetNetworkInfo get network info of task
+func GetNetworkInfo(taskID string) []mesosproto.NetworkInfo {
+	return defaultClient.GetNetworkInfo(taskID)
+}
+
+// GetNetworkInfoContext get network info of task until the context is done
+func GetNetworkInfoContext(ctx context.Context, taskID string) []mesosproto.NetworkInfo {
+	return defaultClient.GetNetworkInfoContext(ctx, taskID)
+}
@@ -0,0 +1,58 @@
+package mesosutil
+
+import (
+	"bytes"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+// Unmarshaler to deserialize the JSON events of mesos
+var unmarshaller = jsonpb.Unmarshaler{
+	AllowUnknownFields: true,
+}
+
+// Codec serialize the calls we send to mesos and deserialize the events
+// we get from mesos
+type Codec interface {
+	// ContentType is the media type of the codec
+	ContentType() string
+	Marshal(message proto.Message) ([]byte, error)
+	Unmarshal(data []byte, message proto.Message) error
+}
+
+// JSONCodec send and receive application/json
+var JSONCodec Codec = jsonCodec{}
+
+// ProtobufCodec send and receive application/x-protobuf
+var ProtobufCodec Codec = protobufCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Marshal(message proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	err := marshaller.Marshal(&buf, message)
+	return buf.Bytes(), err
+}
+
+func (jsonCodec) Unmarshal(data []byte, message proto.Message) error {
+	return unmarshaller.Unmarshal(bytes.NewReader(data), message)
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protobufCodec) Marshal(message proto.Message) ([]byte, error) {
+	return proto.Marshal(message)
+}
+
+func (protobufCodec) Unmarshal(data []byte, message proto.Message) error {
+	return proto.Unmarshal(data, message)
+}
@@ -0,0 +1,162 @@
+package mesosutil
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// largeAcceptCall build an ACCEPT call which launch many tasks
+func largeAcceptCall(tasks int) *mesosproto.Call {
+	c := NewClient(&FrameworkConfig{})
+	offer := mesosproto.Offer{
+		ID:      mesosproto.OfferID{Value: "offer-1"},
+		AgentID: mesosproto.AgentID{Value: "agent-1"},
+	}
+
+	var infos []mesosproto.TaskInfo
+	for n := 0; n < tasks; n++ {
+		cmd := Command{
+			TaskName:       "task",
+			TaskID:         "task." + strconv.Itoa(n),
+			ContainerType:  "docker",
+			ContainerImage: "alpine:latest",
+			Command:        "sleep 1000",
+			Shell:          true,
+			NetworkMode:    "bridge",
+			CPU:            0.1,
+			Memory:         32,
+			DockerPortMappings: []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+				{HostPort: uint32(31000 + n), ContainerPort: 80, Protocol: proto.String("tcp")},
+			},
+			Environment: mesosproto.Environment{Variables: []mesosproto.Environment_Variable{
+				{Name: "INSTANCE", Value: proto.String(strconv.Itoa(n))},
+			}},
+			Labels: []mesosproto.Label{{Key: "instance", Value: proto.String(strconv.Itoa(n))}},
+		}
+		infos = append(infos, c.prepareTaskInfo(cmd, offer, taskResources(cmd)))
+	}
+
+	return AcceptOffer([]mesosproto.OfferID{offer.ID}, []mesosproto.Offer_Operation{LaunchOperation(infos...)}, RefuseFilters(0))
+}
+
+func TestCodecRoundTripEvent(t *testing.T) {
+	events := []mesosproto.Event{
+		{
+			Type: mesosproto.Event_SUBSCRIBED,
+			Subscribed: &mesosproto.Event_Subscribed{
+				FrameworkID:              &mesosproto.FrameworkID{Value: "framework-1"},
+				HeartbeatIntervalSeconds: proto.Float64(15),
+			},
+		},
+		{
+			Type: mesosproto.Event_OFFERS,
+			Offers: &mesosproto.Event_Offers{Offers: []mesosproto.Offer{{
+				ID:          mesosproto.OfferID{Value: "offer-1"},
+				FrameworkID: mesosproto.FrameworkID{Value: "framework-1"},
+				AgentID:     mesosproto.AgentID{Value: "agent-1"},
+				Hostname:    "agent1.example.com",
+				Resources:   []mesosproto.Resource{scalarResource("cpus", 4), scalarResource("mem", 4096)},
+			}}},
+		},
+		{Type: mesosproto.Event_HEARTBEAT},
+	}
+
+	for _, codec := range []Codec{JSONCodec, ProtobufCodec} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			var stream bytes.Buffer
+			for _, event := range events {
+				record, err := codec.Marshal(&event)
+				if err != nil {
+					t.Fatal(err)
+				}
+				stream.WriteString(strconv.Itoa(len(record)) + "\n")
+				stream.Write(record)
+			}
+
+			reader := newRecordIOReader(&stream)
+			for _, want := range events {
+				record, err := reader.ReadRecord()
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got mesosproto.Event
+				if err := codec.Unmarshal(record, &got); err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(&got, &want) {
+					t.Errorf("got %v, want %v", &got, &want)
+				}
+			}
+			if _, err := reader.ReadRecord(); err == nil {
+				t.Error("read a record after the end of the stream")
+			}
+		})
+	}
+}
+
+func TestCodecRoundTripAccept(t *testing.T) {
+	want := largeAcceptCall(3)
+	for _, codec := range []Codec{JSONCodec, ProtobufCodec} {
+		data, err := codec.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got mesosproto.Call
+		if err := codec.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(&got, want) {
+			t.Errorf("%s: call changed in the round trip", codec.ContentType())
+		}
+	}
+}
+
+func benchmarkMarshal(b *testing.B, codec Codec) {
+	call := largeAcceptCall(500)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, err := codec.Marshal(call)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(data)))
+	}
+}
+
+func benchmarkUnmarshal(b *testing.B, codec Codec) {
+	data, err := codec.Marshal(largeAcceptCall(500))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var call mesosproto.Call
+		if err := codec.Unmarshal(data, &call); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONCodecMarshal(b *testing.B) {
+	benchmarkMarshal(b, JSONCodec)
+}
+
+func BenchmarkProtobufCodecMarshal(b *testing.B) {
+	benchmarkMarshal(b, ProtobufCodec)
+}
+
+func BenchmarkJSONCodecUnmarshal(b *testing.B) {
+	benchmarkUnmarshal(b, JSONCodec)
+}
+
+func BenchmarkProtobufCodecUnmarshal(b *testing.B) {
+	benchmarkUnmarshal(b, ProtobufCodec)
+}
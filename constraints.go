@@ -0,0 +1,186 @@
+package mesosutil
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Operators of a placement constraint
+const (
+	// ConstraintLike need a field which match the regular expression Value
+	ConstraintLike = "LIKE"
+	// ConstraintUnlike need a field which does not match the regular
+	// expression Value, or an agent without the field
+	ConstraintUnlike = "UNLIKE"
+	// ConstraintUnique place every instance on an other value of the field
+	ConstraintUnique = "UNIQUE"
+	// ConstraintCluster place all instances on the same value of the field,
+	// or on the given Value
+	ConstraintCluster = "CLUSTER"
+	// ConstraintGroupBy distribute the instances evenly over the values of
+	// the field. Value can set the number of expected values.
+	ConstraintGroupBy = "GROUP_BY"
+	// ConstraintMaxPer place at most Value instances on one value of the field
+	ConstraintMaxPer = "MAX_PER"
+)
+
+// Constraint limit the agents a command can be placed on. The Field is
+// "hostname" or the name of an attribute of the agent.
+type Constraint struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+// OfferAttributes return the hostname and the attributes of the agent of
+// the offer as text
+func OfferAttributes(offer mesosproto.Offer) map[string]string {
+	attributes := map[string]string{"hostname": offer.GetHostname()}
+	for _, attr := range offer.Attributes {
+		attributes[attr.GetName()] = attributeValue(attr)
+	}
+	return attributes
+}
+
+// attributeValue return the value of the attribute as text
+func attributeValue(attr mesosproto.Attribute) string {
+	switch {
+	case attr.Text != nil:
+		return attr.Text.GetValue()
+	case attr.Scalar != nil:
+		return strconv.FormatFloat(attr.Scalar.GetValue(), 'f', -1, 64)
+	case attr.Ranges != nil:
+		var ranges []string
+		for _, r := range attr.Ranges.Range {
+			ranges = append(ranges, strconv.FormatUint(r.Begin, 10)+"-"+strconv.FormatUint(r.End, 10))
+		}
+		return "[" + strings.Join(ranges, ",") + "]"
+	case attr.Set != nil:
+		return "{" + strings.Join(attr.Set.Item, ",") + "}"
+	}
+	return ""
+}
+
+// MatchConstraints check if the command can be placed on the agent of the
+// offer. The instances of the command in State are taken into account.
+func (c *Client) MatchConstraints(offer mesosproto.Offer, cmd Command) bool {
+	attributes := OfferAttributes(offer)
+	return matchConstraints(attributes, cmd, c.placedAttributes()[cmd.TaskName], []map[string]string{attributes})
+}
+
+// MatchConstraints check the constraints with the default client
+func MatchConstraints(offer mesosproto.Offer, cmd Command) bool {
+	return defaultClient.MatchConstraints(offer, cmd)
+}
+
+// placedAttributes return the agent attributes of the tasks in State which
+// are not terminated, by task name
+func (c *Client) placedAttributes() map[string][]map[string]string {
+	placed := make(map[string][]map[string]string)
+	for _, task := range c.config.State {
+		if task.Command.AgentAttributes == nil || (task.Status != nil && isTerminal(task.Status.GetState())) {
+			continue
+		}
+		placed[task.Command.TaskName] = append(placed[task.Command.TaskName], task.Command.AgentAttributes)
+	}
+	return placed
+}
+
+// isTerminal check if the task is not running anymore
+func isTerminal(state mesosproto.TaskState) bool {
+	switch state {
+	case mesosproto.TASK_FINISHED, mesosproto.TASK_FAILED, mesosproto.TASK_KILLED,
+		mesosproto.TASK_ERROR, mesosproto.TASK_DROPPED, mesosproto.TASK_GONE,
+		mesosproto.TASK_GONE_BY_OPERATOR:
+		return true
+	}
+	return false
+}
+
+// matchConstraints check all constraints of the command against the
+// attributes of an agent. placed are the attributes of the agents the other
+// instances of the command are running on, available are the attributes of
+// the agents of all offers the command could be placed on.
+func matchConstraints(attributes map[string]string, cmd Command, placed []map[string]string, available []map[string]string) bool {
+	for _, constraint := range cmd.Constraints {
+		if !constraint.match(attributes, placed, available) {
+			return false
+		}
+	}
+	return true
+}
+
+// match check the constraint against the attributes of an agent
+func (constraint Constraint) match(attributes map[string]string, placed []map[string]string, available []map[string]string) bool {
+	value, ok := attributes[constraint.Field]
+
+	switch strings.ToUpper(constraint.Operator) {
+	case ConstraintLike, ConstraintUnlike:
+		re, err := regexp.Compile("^(?:" + constraint.Value + ")$")
+		if err != nil {
+			logrus.WithField("func", "matchConstraints").Error("Invalid constraint value ", constraint.Value, ": ", err.Error())
+			return false
+		}
+		if strings.ToUpper(constraint.Operator) == ConstraintLike {
+			return ok && re.MatchString(value)
+		}
+		return !ok || !re.MatchString(value)
+	}
+
+	if !ok {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, p := range placed {
+		if v, ok := p[constraint.Field]; ok {
+			counts[v]++
+		}
+	}
+
+	switch strings.ToUpper(constraint.Operator) {
+	case ConstraintUnique:
+		return counts[value] == 0
+	case ConstraintCluster:
+		if constraint.Value != "" {
+			return value == constraint.Value
+		}
+		return len(counts) == 0 || counts[value] > 0
+	case ConstraintGroupBy:
+		// use the value with the fewest instances. The values of the
+		// available offers without instances are empty groups, and so is
+		// every expected value which was not seen yet.
+		for _, a := range available {
+			if v, ok := a[constraint.Field]; ok {
+				if _, used := counts[v]; !used {
+					counts[v] = 0
+				}
+			}
+		}
+		groups, _ := strconv.Atoi(constraint.Value)
+		min := -1
+		for _, count := range counts {
+			if min < 0 || count < min {
+				min = count
+			}
+		}
+		if min < 0 || len(counts) < groups {
+			min = 0
+		}
+		return counts[value] <= min
+	case ConstraintMaxPer:
+		max, err := strconv.Atoi(constraint.Value)
+		if err != nil {
+			logrus.WithField("func", "matchConstraints").Error("Invalid constraint value ", constraint.Value, ": ", err.Error())
+			return false
+		}
+		return counts[value] < max
+	}
+
+	logrus.WithField("func", "matchConstraints").Error("Unknown constraint operator ", constraint.Operator)
+	return false
+}
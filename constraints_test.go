@@ -0,0 +1,142 @@
+package mesosutil
+
+import (
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+// rackOffer create an offer of an agent with the rack attribute
+func rackOffer(id string, rack string, cpus float64) mesosproto.Offer {
+	offer := testOffer(id, "host-"+id, cpus, 4096)
+	offer.Attributes = []mesosproto.Attribute{{
+		Name: "rack",
+		Type: mesosproto.TEXT,
+		Text: &mesosproto.Value_Text{Value: rack},
+	}}
+	return offer
+}
+
+// racks return the attributes of agents in the racks
+func racks(names ...string) []map[string]string {
+	var attributes []map[string]string
+	for _, name := range names {
+		attributes = append(attributes, map[string]string{"hostname": "host-" + name, "rack": name})
+	}
+	return attributes
+}
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint Constraint
+		attributes map[string]string
+		placed     []map[string]string
+		available  []map[string]string
+		want       bool
+	}{
+		{"like", Constraint{"rack", ConstraintLike, "a|b"}, map[string]string{"rack": "a"}, nil, nil, true},
+		{"like other value", Constraint{"rack", ConstraintLike, "a|b"}, map[string]string{"rack": "c"}, nil, nil, false},
+		{"like without field", Constraint{"rack", ConstraintLike, "a"}, map[string]string{}, nil, nil, false},
+		{"like invalid regexp", Constraint{"rack", ConstraintLike, "("}, map[string]string{"rack": "a"}, nil, nil, false},
+		{"unlike", Constraint{"rack", ConstraintUnlike, "a"}, map[string]string{"rack": "b"}, nil, nil, true},
+		{"unlike same value", Constraint{"rack", "unlike", "a"}, map[string]string{"rack": "a"}, nil, nil, false},
+		{"unlike without field", Constraint{"rack", ConstraintUnlike, "a"}, map[string]string{}, nil, nil, true},
+		{"unique", Constraint{"hostname", ConstraintUnique, ""}, map[string]string{"hostname": "host-b"}, racks("a"), nil, true},
+		{"unique used value", Constraint{"hostname", ConstraintUnique, ""}, map[string]string{"hostname": "host-a"}, racks("a"), nil, false},
+		{"unique without field", Constraint{"zone", ConstraintUnique, ""}, map[string]string{"rack": "a"}, nil, nil, false},
+		{"cluster first instance", Constraint{"rack", ConstraintCluster, ""}, map[string]string{"rack": "b"}, nil, nil, true},
+		{"cluster same value", Constraint{"rack", ConstraintCluster, ""}, map[string]string{"rack": "a"}, racks("a"), nil, true},
+		{"cluster other value", Constraint{"rack", ConstraintCluster, ""}, map[string]string{"rack": "b"}, racks("a"), nil, false},
+		{"cluster given value", Constraint{"rack", ConstraintCluster, "b"}, map[string]string{"rack": "b"}, nil, nil, true},
+		{"cluster not given value", Constraint{"rack", ConstraintCluster, "b"}, map[string]string{"rack": "a"}, nil, nil, false},
+		{"group by first instance", Constraint{"rack", ConstraintGroupBy, ""}, map[string]string{"rack": "a"}, nil, racks("a", "b"), true},
+		{"group by available empty value", Constraint{"rack", ConstraintGroupBy, ""}, map[string]string{"rack": "a"}, racks("a"), racks("a", "b"), false},
+		{"group by empty value", Constraint{"rack", ConstraintGroupBy, ""}, map[string]string{"rack": "b"}, racks("a"), racks("a", "b"), true},
+		{"group by only value available", Constraint{"rack", ConstraintGroupBy, ""}, map[string]string{"rack": "a"}, racks("a", "b"), racks("a"), true},
+		{"group by balanced", Constraint{"rack", ConstraintGroupBy, ""}, map[string]string{"rack": "a"}, racks("a", "b"), racks("a", "b"), true},
+		{"group by expected value missing", Constraint{"rack", ConstraintGroupBy, "3"}, map[string]string{"rack": "a"}, racks("a", "b"), racks("a"), false},
+		{"group by unknown value", Constraint{"rack", ConstraintGroupBy, ""}, map[string]string{"rack": "c"}, racks("a", "b"), racks("c"), true},
+		{"max per", Constraint{"rack", ConstraintMaxPer, "2"}, map[string]string{"rack": "a"}, racks("a"), nil, true},
+		{"max per full", Constraint{"rack", ConstraintMaxPer, "2"}, map[string]string{"rack": "a"}, racks("a", "a"), nil, false},
+		{"max per invalid value", Constraint{"rack", ConstraintMaxPer, "two"}, map[string]string{"rack": "a"}, nil, nil, false},
+		{"unknown operator", Constraint{"rack", "NEAR", ""}, map[string]string{"rack": "a"}, nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.constraint.match(tt.attributes, tt.placed, tt.available); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackOffersGroupBy(t *testing.T) {
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{
+		rackOffer("a1", "a", 8),
+		rackOffer("b1", "b", 8),
+	}}
+	cmd := Command{
+		TaskName:    "web",
+		CPU:         1,
+		Memory:      128,
+		Instances:   4,
+		Constraints: []Constraint{{Field: "rack", Operator: ConstraintGroupBy}},
+	}
+
+	for _, strategy := range []PackingStrategy{FirstFit, BinPack, Spread} {
+		c := NewClient(&FrameworkConfig{})
+		plan := c.PackOffers(offers, []Command{cmd}, strategy)
+		if len(plan.Matches) != 4 {
+			t.Fatalf("strategy %d: got %d matches, want 4", strategy, len(plan.Matches))
+		}
+		perRack := make(map[string]int)
+		for _, match := range plan.Matches {
+			perRack[match.Command.AgentAttributes["rack"]]++
+		}
+		if perRack["a"] != 2 || perRack["b"] != 2 {
+			t.Errorf("strategy %d: got %v instances per rack, want 2 on a and b", strategy, perRack)
+		}
+	}
+}
+
+func TestPackOffersGroupByFullValue(t *testing.T) {
+	// rack b has only room for one instance, the rest has to go to rack a
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{
+		rackOffer("a1", "a", 8),
+		rackOffer("b1", "b", 1),
+	}}
+	cmd := Command{
+		TaskName:    "web",
+		CPU:         1,
+		Memory:      128,
+		Instances:   3,
+		Constraints: []Constraint{{Field: "rack", Operator: ConstraintGroupBy}},
+	}
+
+	plan := NewClient(&FrameworkConfig{}).PackOffers(offers, []Command{cmd}, BinPack)
+	if len(plan.Matches) != 3 || len(plan.Unmatched) != 0 {
+		t.Fatalf("got %d matches and %d unmatched, want 3 matches", len(plan.Matches), len(plan.Unmatched))
+	}
+}
+
+func TestPlanOffersGroupByRunningInstances(t *testing.T) {
+	c := NewClient(&FrameworkConfig{State: map[string]State{
+		"web.0": {Command: Command{TaskName: "web", AgentAttributes: map[string]string{"rack": "a"}}},
+	}})
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{
+		rackOffer("a1", "a", 8),
+		rackOffer("b1", "b", 8),
+	}}
+	cmd := Command{
+		TaskName:    "web",
+		CPU:         1,
+		Memory:      128,
+		Constraints: []Constraint{{Field: "rack", Operator: ConstraintGroupBy}},
+	}
+
+	plan := c.PlanOffers(offers, []Command{cmd})
+	if len(plan.Matches) != 1 || plan.Matches[0].Offer.ID.Value != "b1" {
+		t.Fatalf("got matches %v, want the offer of rack b", plan.Matches)
+	}
+}
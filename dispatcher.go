@@ -0,0 +1,154 @@
+package mesosutil
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventHandler handle one event of the mesos master
+type EventHandler func(event *mesosproto.Event) error
+
+// Middleware wrap an EventHandler, to run code before and after the handler
+type Middleware func(next EventHandler) EventHandler
+
+// Dispatcher call the registered handler of every event type. The handlers
+// and middleware have to be registered before the dispatcher is running.
+type Dispatcher struct {
+	handlers       map[mesosproto.Event_Type]EventHandler
+	defaultHandler EventHandler
+	middleware     []Middleware
+}
+
+// NewDispatcher create an empty event dispatcher
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers: make(map[mesosproto.Event_Type]EventHandler),
+	}
+}
+
+// Handle register the handler for the given event type
+func (d *Dispatcher) Handle(eventType mesosproto.Event_Type, handler EventHandler) *Dispatcher {
+	d.handlers[eventType] = handler
+	return d
+}
+
+// HandleDefault register the handler for all event types without an own handler
+func (d *Dispatcher) HandleDefault(handler EventHandler) *Dispatcher {
+	d.defaultHandler = handler
+	return d
+}
+
+// Use add middleware around all handlers. The first middleware is the outermost.
+func (d *Dispatcher) Use(middleware ...Middleware) *Dispatcher {
+	d.middleware = append(d.middleware, middleware...)
+	return d
+}
+
+// Dispatch call the handler of the event. The middleware run for every
+// event, even if there is no handler for its type.
+func (d *Dispatcher) Dispatch(event *mesosproto.Event) error {
+	handler, ok := d.handlers[event.Type]
+	if !ok {
+		handler = d.defaultHandler
+	}
+	if handler == nil {
+		handler = unhandledEvent
+	}
+
+	for i := len(d.middleware) - 1; i >= 0; i-- {
+		handler = d.middleware[i](handler)
+	}
+	return handler(event)
+}
+
+// unhandledEvent is the handler of the events without a handler
+func unhandledEvent(event *mesosproto.Event) error {
+	logrus.WithField("func", "Dispatch").Debug("No handler for event: ", event.Type.String())
+	return nil
+}
+
+// Run dispatch all events of the subscription until the stream is closed.
+// It return the error of the subscription.
+func (d *Dispatcher) Run(sub *Subscription) error {
+	for event := range sub.Events() {
+		event := event
+		err := d.Dispatch(&event)
+		if err != nil {
+			logrus.WithField("func", "Dispatcher.Run").Error("Handle event ", event.Type.String(), ": ", err.Error())
+		}
+	}
+	return sub.Err()
+}
+
+// LoggingMiddleware log every event and the errors of the handlers
+func LoggingMiddleware(next EventHandler) EventHandler {
+	return func(event *mesosproto.Event) error {
+		logrus.WithField("func", "LoggingMiddleware").Debug("Event: ", event.Type.String())
+		err := next(event)
+		if err != nil {
+			logrus.WithField("func", "LoggingMiddleware").Error("Event ", event.Type.String(), ": ", err.Error())
+		}
+		return err
+	}
+}
+
+// RecoveryMiddleware turn a panic of a handler into an error
+func RecoveryMiddleware(next EventHandler) EventHandler {
+	return func(event *mesosproto.Event) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logrus.WithField("func", "RecoveryMiddleware").Error("Panic in handler of ", event.Type.String(), ": ", r, "\n", string(debug.Stack()))
+				err = fmt.Errorf("panic in handler of %s: %v", event.Type.String(), r)
+			}
+		}()
+		return next(event)
+	}
+}
+
+// EventMetrics count the handled events and errors per event type
+type EventMetrics struct {
+	mu     sync.Mutex
+	events map[mesosproto.Event_Type]uint64
+	errors map[mesosproto.Event_Type]uint64
+}
+
+// NewEventMetrics create an empty event counter
+func NewEventMetrics() *EventMetrics {
+	return &EventMetrics{
+		events: make(map[mesosproto.Event_Type]uint64),
+		errors: make(map[mesosproto.Event_Type]uint64),
+	}
+}
+
+// Middleware return the middleware which count the events
+func (m *EventMetrics) Middleware(next EventHandler) EventHandler {
+	return func(event *mesosproto.Event) error {
+		err := next(event)
+		m.mu.Lock()
+		m.events[event.Type]++
+		if err != nil {
+			m.errors[event.Type]++
+		}
+		m.mu.Unlock()
+		return err
+	}
+}
+
+// Events return how many events of the given type were handled
+func (m *EventMetrics) Events(eventType mesosproto.Event_Type) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.events[eventType]
+}
+
+// Errors return how many handlers of the given event type failed
+func (m *EventMetrics) Errors(eventType mesosproto.Event_Type) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.errors[eventType]
+}
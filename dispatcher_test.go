@@ -0,0 +1,106 @@
+package mesosutil
+
+import (
+	"errors"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+func TestDispatchCallsHandlerByType(t *testing.T) {
+	var got []mesosproto.Event_Type
+	d := NewDispatcher().
+		Handle(mesosproto.Event_OFFERS, func(event *mesosproto.Event) error {
+			got = append(got, event.Type)
+			return nil
+		}).
+		HandleDefault(func(event *mesosproto.Event) error {
+			got = append(got, mesosproto.Event_UNKNOWN)
+			return nil
+		})
+
+	d.Dispatch(&mesosproto.Event{Type: mesosproto.Event_OFFERS})
+	d.Dispatch(&mesosproto.Event{Type: mesosproto.Event_HEARTBEAT})
+
+	if len(got) != 2 || got[0] != mesosproto.Event_OFFERS || got[1] != mesosproto.Event_UNKNOWN {
+		t.Errorf("handled %v, want [OFFERS UNKNOWN]", got)
+	}
+}
+
+func TestDispatchRunsMiddlewareWithoutHandler(t *testing.T) {
+	metrics := NewEventMetrics()
+	d := NewDispatcher().Use(metrics.Middleware)
+
+	err := d.Dispatch(&mesosproto.Event{Type: mesosproto.Event_UPDATE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := metrics.Events(mesosproto.Event_UPDATE); got != 1 {
+		t.Errorf("Events(UPDATE) = %d, want 1", got)
+	}
+}
+
+func TestDispatchMiddlewareOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next EventHandler) EventHandler {
+			return func(event *mesosproto.Event) error {
+				order = append(order, name)
+				return next(event)
+			}
+		}
+	}
+	d := NewDispatcher().Use(mark("outer"), mark("inner"))
+	d.Handle(mesosproto.Event_HEARTBEAT, func(event *mesosproto.Event) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	d.Dispatch(&mesosproto.Event{Type: mesosproto.Event_HEARTBEAT})
+	if len(order) != 3 || order[0] != "outer" || order[1] != "inner" || order[2] != "handler" {
+		t.Errorf("order = %v, want [outer inner handler]", order)
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	metrics := NewEventMetrics()
+	d := NewDispatcher().Use(metrics.Middleware, RecoveryMiddleware)
+	d.Handle(mesosproto.Event_ERROR, func(event *mesosproto.Event) error {
+		panic("boom")
+	})
+	d.Handle(mesosproto.Event_FAILURE, func(event *mesosproto.Event) error {
+		return errors.New("failed")
+	})
+
+	if err := d.Dispatch(&mesosproto.Event{Type: mesosproto.Event_ERROR}); err == nil {
+		t.Error("panic was not turned into an error")
+	}
+	if err := d.Dispatch(&mesosproto.Event{Type: mesosproto.Event_FAILURE}); err == nil {
+		t.Error("error of the handler was lost")
+	}
+	if metrics.Errors(mesosproto.Event_ERROR) != 1 || metrics.Errors(mesosproto.Event_FAILURE) != 1 {
+		t.Error("errors were not counted")
+	}
+}
+
+func TestDispatchAcknowledgeWithoutHandler(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+	d := NewDispatcher().Use(c.AcknowledgeMiddleware)
+
+	err := d.Dispatch(&mesosproto.Event{
+		Type: mesosproto.Event_UPDATE,
+		Update: &mesosproto.Event_Update{Status: mesosproto.TaskStatus{
+			TaskID:  mesosproto.TaskID{Value: "task-1"},
+			AgentID: &mesosproto.AgentID{Value: "agent-1"},
+			UUID:    []byte("uuid"),
+			State:   mesosproto.TASK_RUNNING.Enum(),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(master.received(mesosproto.Call_ACKNOWLEDGE)); got != 1 {
+		t.Errorf("got %d ACKNOWLEDGE calls, want 1", got)
+	}
+}
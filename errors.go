@@ -0,0 +1,98 @@
+package mesosutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+// maxErrorBody is how much of the response body is kept in an APIError
+const maxErrorBody = 64 << 10
+
+// APIError is returned if the mesos master reject a request
+type APIError struct {
+	StatusCode int
+	Body       string
+	CallType   mesosproto.Call_Type
+}
+
+func (e *APIError) Error() string {
+	body := strings.TrimSpace(e.Body)
+	if body == "" {
+		return fmt.Sprintf("mesos %s call failed with status %d", e.CallType.String(), e.StatusCode)
+	}
+	return fmt.Sprintf("mesos %s call failed with status %d: %s", e.CallType.String(), e.StatusCode, body)
+}
+
+// newAPIError read the body of the response into an APIError
+func newAPIError(res *http.Response, callType mesosproto.Call_Type) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	return &APIError{
+		StatusCode: res.StatusCode,
+		Body:       string(body),
+		CallType:   callType,
+	}
+}
+
+// IsRetryable return true if the request may succeed if it is send again.
+// A canceled or expired context is final, CallContext retry the Timeout of
+// a single attempt on its own.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+			http.StatusTemporaryRedirect:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// IsNotLeader return true if the master was not the leading master, or
+// the mesos cluster has no leading master at the moment
+func IsNotLeader(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTemporaryRedirect || apiErr.StatusCode == http.StatusServiceUnavailable
+}
+
+// IsUnauthorized return true if the credentials were rejected by the master
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden
+}
+
+// IsBadRequest return true if the master rejected the content of the call
+func IsBadRequest(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusBadRequest
+}
@@ -0,0 +1,86 @@
+package mesosutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+// fakeMaster is a mesos master which accept every call and stream the
+// given events to every subscription
+type fakeMaster struct {
+	*httptest.Server
+	events []mesosproto.Event
+
+	mu      sync.Mutex
+	calls   []mesosproto.Call
+	streams []string
+}
+
+func newFakeMaster(t testing.TB, events ...mesosproto.Event) *fakeMaster {
+	m := &fakeMaster{events: events}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serve))
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *fakeMaster) serve(w http.ResponseWriter, r *http.Request) {
+	codec := JSONCodec
+	if r.Header.Get("Content-Type") == ProtobufCodec.ContentType() {
+		codec = ProtobufCodec
+	}
+	body, _ := io.ReadAll(r.Body)
+
+	var call mesosproto.Call
+	if err := codec.Unmarshal(body, &call); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	m.streams = append(m.streams, r.Header.Get("Mesos-Stream-Id"))
+	m.mu.Unlock()
+
+	if call.Type != mesosproto.Call_SUBSCRIBE {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", codec.ContentType())
+	w.Header().Set("Mesos-Stream-Id", "stream-1")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range m.events {
+		record, _ := codec.Marshal(&event)
+		io.WriteString(w, strconv.Itoa(len(record))+"\n")
+		w.Write(record)
+	}
+	w.(http.Flusher).Flush()
+	<-r.Context().Done()
+}
+
+// config return a framework config of the fake master
+func (m *fakeMaster) config() *FrameworkConfig {
+	return &FrameworkConfig{
+		MesosMasterServer: strings.TrimPrefix(m.URL, "http://"),
+		FrameworkRole:     "web",
+	}
+}
+
+// received return the calls of the given type the master got
+func (m *fakeMaster) received(callType mesosproto.Call_Type) []mesosproto.Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []mesosproto.Call
+	for _, call := range m.calls {
+		if call.Type == callType {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
@@ -0,0 +1,128 @@
+package mesosutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// RefuseFilters create offer filters, so mesos will not offer the declined
+// resources again for the given duration
+func RefuseFilters(refuse time.Duration) *mesosproto.Filters {
+	return &mesosproto.Filters{
+		RefuseSeconds: proto.Float64(refuse.Seconds()),
+	}
+}
+
+// RefusePolicy adapt the refuse duration of declined offers. While there
+// is pending work, offers are refused only for Min. Every decline without
+// pending work multiply the refuse duration by Factor, up to Max.
+type RefusePolicy struct {
+	Min    time.Duration
+	Max    time.Duration
+	Factor float64
+
+	mu      sync.Mutex
+	current time.Duration
+}
+
+// NewRefusePolicy create a refuse policy between min and max
+func NewRefusePolicy(min time.Duration, max time.Duration) *RefusePolicy {
+	return &RefusePolicy{
+		Min:    min,
+		Max:    max,
+		Factor: 2,
+	}
+}
+
+// Next return the refuse duration for the next decline
+func (p *RefusePolicy) Next(pending bool) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pending || p.current < p.Min {
+		p.current = p.Min
+		return p.current
+	}
+
+	p.current = time.Duration(float64(p.current) * p.Factor)
+	if p.current > p.Max {
+		p.current = p.Max
+	}
+	return p.current
+}
+
+// Reset the refuse duration to Min
+func (p *RefusePolicy) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = 0
+}
+
+// RefuseDuration return how long declined offers should be refused. It is
+// longer the more often there was no pending work in the CommandChan.
+func (c *Client) RefuseDuration() time.Duration {
+	return c.refuseDuration(false)
+}
+
+// refuseDuration return the refuse duration of the policy. unmatched say
+// that there is work which was already taken out of the CommandChan.
+func (c *Client) refuseDuration(unmatched bool) time.Duration {
+	pending := unmatched || (c.config.CommandChan != nil && len(c.config.CommandChan) > 0)
+	return c.Refuse.Next(pending)
+}
+
+// DeclineOfferFilters will decline the given offers with the filters
+func DeclineOfferFilters(offerIds []mesosproto.OfferID, filters *mesosproto.Filters) *mesosproto.Call {
+	decline := DeclineOffer(offerIds)
+	decline.Decline.Filters = filters
+	return decline
+}
+
+// Decline the offers and refuse them for the given duration
+func (c *Client) Decline(offerIds []mesosproto.OfferID, refuse time.Duration) error {
+	return c.DeclineContext(context.Background(), offerIds, refuse)
+}
+
+// DeclineContext decline the offers until the context is done
+func (c *Client) DeclineContext(ctx context.Context, offerIds []mesosproto.OfferID, refuse time.Duration) error {
+	if len(offerIds) == 0 {
+		return nil
+	}
+	logrus.WithField("func", "Decline").Debug("Decline ", len(offerIds), " offers for ", refuse)
+	return c.CallContext(ctx, DeclineOfferFilters(offerIds, RefuseFilters(refuse)))
+}
+
+// DeclineAdaptive decline the offers with the refuse duration of the refuse policy
+func (c *Client) DeclineAdaptive(offerIds []mesosproto.OfferID) error {
+	return c.DeclineAdaptiveContext(context.Background(), offerIds)
+}
+
+// DeclineAdaptiveContext decline the offers with the refuse policy until the context is done
+func (c *Client) DeclineAdaptiveContext(ctx context.Context, offerIds []mesosproto.OfferID) error {
+	return c.declineAdaptive(ctx, offerIds, false)
+}
+
+// declineAdaptive decline the offers with the refuse policy. unmatched
+// commands count as pending work, so the offers are refused only shortly.
+func (c *Client) declineAdaptive(ctx context.Context, offerIds []mesosproto.OfferID, unmatched bool) error {
+	if len(offerIds) == 0 {
+		return nil
+	}
+	return c.DeclineContext(ctx, offerIds, c.refuseDuration(unmatched))
+}
+
+// Decline the offers with the default client
+func Decline(offerIds []mesosproto.OfferID, refuse time.Duration) error {
+	return defaultClient.Decline(offerIds, refuse)
+}
+
+// DeclineAdaptive decline the offers with the default client
+func DeclineAdaptive(offerIds []mesosproto.OfferID) error {
+	return defaultClient.DeclineAdaptive(offerIds)
+}
@@ -0,0 +1,133 @@
+package mesosutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultHeartbeatInterval is used until mesos told us the real interval
+var DefaultHeartbeatInterval = 15 * time.Second
+
+// MaxMissedHeartbeats is how many heartbeats can be missed before the
+// connection to the mesos master is treated as dead
+var MaxMissedHeartbeats = 5
+
+// Run subscribe to the mesos master and dispatch all events. If the
+// connection get lost or the heartbeats of mesos are missing, the dead
+// connection will be closed and the framework resubscribe with its stored
+// framework id. Run return if the stop channel get closed.
+func (c *Client) Run(d *Dispatcher, stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := c.RunContext(ctx, d)
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// RunContext is like Run, but it return the error of the context if the
+// context is done.
+func (c *Client) RunContext(ctx context.Context, d *Dispatcher) error {
+	attempt := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Backoff.Duration(attempt)):
+		}
+		attempt++
+
+		subCtx, cancel := context.WithCancel(ctx)
+		sub, err := c.SubscribeContext(subCtx)
+		if err != nil {
+			cancel()
+			logrus.WithField("func", "Run").Error("Subscribe: ", err.Error())
+			continue
+		}
+
+		subscribed, err := c.watch(ctx, sub, d)
+		cancel()
+		if subscribed {
+			attempt = 1
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logrus.WithField("func", "Run").Warn("Lost connection to mesos master, resubscribe: ", err.Error())
+	}
+}
+
+// Run the default client
+func Run(d *Dispatcher, stop <-chan struct{}) error {
+	return defaultClient.Run(d, stop)
+}
+
+// RunContext run the default client until the context is done
+func RunContext(ctx context.Context, d *Dispatcher) error {
+	return defaultClient.RunContext(ctx, d)
+}
+
+// watch dispatch the events of the subscription until the connection is
+// dead or the context is done. It return if the framework was subscribed
+// successfully.
+func (c *Client) watch(ctx context.Context, sub *Subscription, d *Dispatcher) (bool, error) {
+	defer sub.Close()
+
+	subscribed := false
+	interval := DefaultHeartbeatInterval
+	timeout := interval * time.Duration(MaxMissedHeartbeats)
+	watchdog := time.NewTimer(timeout)
+	defer watchdog.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return subscribed, ctx.Err()
+		case <-watchdog.C:
+			return subscribed, fmt.Errorf("missed %d heartbeats", MaxMissedHeartbeats)
+		case event, ok := <-sub.Events():
+			if !ok {
+				err := sub.Err()
+				if err == nil {
+					err = fmt.Errorf("event stream closed by mesos master")
+				}
+				return subscribed, err
+			}
+
+			if event.Type == mesosproto.Event_SUBSCRIBED {
+				subscribed = true
+				if seconds := event.Subscribed.GetHeartbeatIntervalSeconds(); seconds > 0 {
+					interval = time.Duration(seconds * float64(time.Second))
+					timeout = interval * time.Duration(MaxMissedHeartbeats)
+				}
+			}
+
+			err := d.Dispatch(&event)
+			if err != nil {
+				logrus.WithField("func", "watch").Error("Handle event ", event.Type.String(), ": ", err.Error())
+			}
+
+			if !watchdog.Stop() {
+				select {
+				case <-watchdog.C:
+				default:
+				}
+			}
+			watchdog.Reset(timeout)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package mesosutil
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+func TestResubscribeAfterMissedHeartbeats(t *testing.T) {
+	interval, missed := DefaultHeartbeatInterval, MaxMissedHeartbeats
+	defer func() { DefaultHeartbeatInterval, MaxMissedHeartbeats = interval, missed }()
+	DefaultHeartbeatInterval = 20 * time.Millisecond
+	MaxMissedHeartbeats = 2
+
+	// the master send no heartbeats after SUBSCRIBED
+	master := newFakeMaster(t, mesosproto.Event{
+		Type: mesosproto.Event_SUBSCRIBED,
+		Subscribed: &mesosproto.Event_Subscribed{
+			FrameworkID: &mesosproto.FrameworkID{Value: "framework-1"},
+		},
+	})
+	c := NewClient(master.config())
+	c.Backoff = Backoff{Initial: time.Millisecond, Max: time.Millisecond, Factor: 1}
+
+	var subscribed int32
+	d := NewDispatcher().Handle(mesosproto.Event_SUBSCRIBED, func(event *mesosproto.Event) error {
+		atomic.AddInt32(&subscribed, 1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.RunContext(ctx, d) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(master.received(mesosproto.Call_SUBSCRIBE)) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the framework did not resubscribe after the missed heartbeats")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("RunContext() = %v, want context.Canceled", err)
+	}
+
+	calls := master.received(mesosproto.Call_SUBSCRIBE)
+	if calls[0].Subscribe.FrameworkInfo.ID != nil {
+		t.Errorf("first SUBSCRIBE has framework id %q, want none", calls[0].Subscribe.FrameworkInfo.ID.Value)
+	}
+	resubscribe := calls[1]
+	if resubscribe.FrameworkID.GetValue() != "framework-1" || resubscribe.Subscribe.FrameworkInfo.ID.GetValue() != "framework-1" {
+		t.Errorf("resubscribe with framework id %q and framework info id %q, want framework-1",
+			resubscribe.FrameworkID.GetValue(), resubscribe.Subscribe.FrameworkInfo.ID.GetValue())
+	}
+	if got := atomic.LoadInt32(&subscribed); got < 1 {
+		t.Errorf("SUBSCRIBED was dispatched %d times, want at least 1", got)
+	}
+}
+
+func TestHeartbeatIntervalOfSubscribed(t *testing.T) {
+	interval, missed := DefaultHeartbeatInterval, MaxMissedHeartbeats
+	defer func() { DefaultHeartbeatInterval, MaxMissedHeartbeats = interval, missed }()
+	DefaultHeartbeatInterval = time.Hour
+	MaxMissedHeartbeats = 2
+
+	// the interval of SUBSCRIBED replace the DefaultHeartbeatInterval
+	master := newFakeMaster(t, mesosproto.Event{
+		Type: mesosproto.Event_SUBSCRIBED,
+		Subscribed: &mesosproto.Event_Subscribed{
+			FrameworkID:              &mesosproto.FrameworkID{Value: "framework-1"},
+			HeartbeatIntervalSeconds: proto.Float64(0.02),
+		},
+	})
+	c := NewClient(master.config())
+	c.Backoff = Backoff{Initial: time.Millisecond, Max: time.Millisecond, Factor: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.RunContext(ctx, NewDispatcher()) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(master.received(mesosproto.Call_SUBSCRIBE)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if len(master.received(mesosproto.Call_SUBSCRIBE)) < 2 {
+		t.Error("the framework did not resubscribe with the heartbeat interval of mesos")
+	}
+}
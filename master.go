@@ -0,0 +1,210 @@
+package mesosutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+)
+
+// masters return the addresses of all configured mesos masters
+func (c *Client) masters() []string {
+	if len(c.config.MesosMasterServers) > 0 {
+		return c.config.MesosMasterServers
+	}
+	return []string{c.config.MesosMasterServer}
+}
+
+// master return the address of the leading master if we know it, otherwise
+// the first configured master
+func (c *Client) master() string {
+	if leader := c.Leader(); leader != "" {
+		return leader
+	}
+	return c.masters()[0]
+}
+
+// Leader return the cached address of the leading mesos master
+func (c *Client) Leader() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.leader
+}
+
+func (c *Client) setLeader(leader string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.leader != leader {
+		logrus.WithField("func", "setLeader").Info("Leading mesos master: ", leader)
+		c.leader = leader
+	}
+}
+
+// DetectLeader ask the configured masters who is the leading master and
+// cache the answer for the following requests
+func (c *Client) DetectLeader() (string, error) {
+	return c.DetectLeaderContext(context.Background())
+}
+
+// DetectLeaderContext ask the configured masters who is the leading master
+// until the context is done
+func (c *Client) DetectLeaderContext(ctx context.Context) (string, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if c.err != nil {
+		return "", c.err
+	}
+
+	var lastErr error
+	for _, master := range c.masters() {
+		req, _ := http.NewRequestWithContext(ctx, "GET", c.url("/redirect"), nil)
+		req.URL.Host = master
+		if err := c.authenticate(req); err != nil {
+			return "", err
+		}
+		res, err := c.send(req)
+		if err != nil {
+			logrus.WithField("func", "DetectLeader").Debug("Could not connect to master ", master, ": ", err.Error())
+			lastErr = err
+			continue
+		}
+		closeBody(res)
+
+		if res.StatusCode != http.StatusTemporaryRedirect {
+			lastErr = fmt.Errorf("master %s answered with status %d instead of a redirect", master, res.StatusCode)
+			continue
+		}
+
+		leader, err := redirectHost(res)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c.setLeader(leader)
+		return leader, nil
+	}
+	return "", fmt.Errorf("could not detect the leading mesos master: %w", lastErr)
+}
+
+// DetectLeader of the default client
+func DetectLeader() (string, error) {
+	return defaultClient.DetectLeader()
+}
+
+// DetectLeaderContext of the default client
+func DetectLeaderContext(ctx context.Context) (string, error) {
+	return defaultClient.DetectLeaderContext(ctx)
+}
+
+// do send the request to the leading mesos master. A non leading master
+// answer with 307 and the address of the leader, in this case the request
+// will be send again to the leader. If a master is not reachable, the next
+// configured master will be tried.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := c.authenticate(req); err != nil {
+		return nil, err
+	}
+
+	masters := c.masters()
+	tried := map[string]bool{}
+	maxAttempts := 2*len(masters) + 1
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		tried[req.URL.Host] = true
+		res, err := c.send(req)
+
+		if err != nil {
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			lastErr = err
+			next := ""
+			for _, master := range masters {
+				if !tried[master] {
+					next = master
+					break
+				}
+			}
+			if next == "" {
+				return nil, err
+			}
+			logrus.WithField("func", "do").Warn("Could not connect to master ", req.URL.Host, ", try ", next, ": ", err.Error())
+			req = retarget(req, next)
+			continue
+		}
+
+		if res.StatusCode == http.StatusTemporaryRedirect {
+			closeBody(res)
+			leader, err := redirectHost(res)
+			if err != nil {
+				return nil, err
+			}
+			c.setLeader(leader)
+			req = retarget(req, leader)
+			continue
+		}
+
+		c.setLeader(req.URL.Host)
+		return res, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("too many redirects")
+	}
+	return nil, lastErr
+}
+
+// authenticate add the credentials of the client to the request
+func (c *Client) authenticate(req *http.Request) error {
+	if c.Auth == nil {
+		return nil
+	}
+	err := c.Auth.Authenticate(req)
+	if err != nil {
+		return fmt.Errorf("could not authenticate request: %w", err)
+	}
+	return nil
+}
+
+// send the request to the master
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	res, err := c.client.Do(req)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		// let a refreshing authenticator fetch new credentials
+		if auth, ok := c.Auth.(interface{ Invalidate() }); ok {
+			auth.Invalidate()
+		}
+	}
+	return res, err
+}
+
+// redirectHost get the address of the leading master out of a 307 response
+func redirectHost(res *http.Response) (string, error) {
+	location := res.Header.Get("Location")
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect location %q: %w", location, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("redirect location %q without host", location)
+	}
+	return u.Host, nil
+}
+
+// retarget copy the request to send it to another master
+func retarget(req *http.Request, host string) *http.Request {
+	r := req.Clone(req.Context())
+	r.URL.Host = host
+	r.Host = ""
+	if req.GetBody != nil {
+		r.Body, _ = req.GetBody()
+	}
+	return r
+}
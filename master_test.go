@@ -0,0 +1,147 @@
+package mesosutil
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+// redirectingMaster is a non leading master which redirect every request
+// to the leader
+type redirectingMaster struct {
+	*httptest.Server
+	leader   atomic.Value
+	requests int32
+}
+
+func newRedirectingMaster(t testing.TB, leader string) *redirectingMaster {
+	m := &redirectingMaster{}
+	m.leader.Store(leader)
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&m.requests, 1)
+		// mesos answer with a protocol relative location
+		w.Header().Set("Location", "//"+m.leader.Load().(string)+r.URL.Path)
+		w.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	t.Cleanup(m.Close)
+	return m
+}
+
+// host return the address of the test server
+func host(server *httptest.Server) string {
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// unreachableMaster return an address nobody is listening on
+func unreachableMaster(t testing.TB) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestFollowRedirectToLeader(t *testing.T) {
+	leader := newFakeMaster(t)
+	follower := newRedirectingMaster(t, host(leader.Server))
+	c := NewClient(&FrameworkConfig{MesosMasterServers: []string{host(follower.Server)}})
+
+	if err := c.Kill("task-1", "agent-1"); err != nil {
+		t.Fatal(err)
+	}
+	kill := leader.received(mesosproto.Call_KILL)
+	if len(kill) != 1 || kill[0].Kill.TaskID.Value != "task-1" {
+		t.Fatalf("leader got %v, want the KILL call of task-1 with its body", kill)
+	}
+	if c.Leader() != host(leader.Server) {
+		t.Errorf("Leader() = %s, want %s", c.Leader(), host(leader.Server))
+	}
+
+	// the cached leader is used for the next call
+	if err := c.Kill("task-2", "agent-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&follower.requests); got != 1 {
+		t.Errorf("follower got %d requests, want 1", got)
+	}
+	if got := len(leader.received(mesosproto.Call_KILL)); got != 2 {
+		t.Errorf("leader got %d KILL calls, want 2", got)
+	}
+}
+
+func TestFailoverToNextMaster(t *testing.T) {
+	leader := newFakeMaster(t)
+	c := NewClient(&FrameworkConfig{MesosMasterServers: []string{unreachableMaster(t), host(leader.Server)}})
+	c.Retry.MaxAttempts = 1
+
+	if err := c.Accept(nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(leader.received(mesosproto.Call_ACCEPT)); got != 1 {
+		t.Errorf("leader got %d ACCEPT calls, want 1", got)
+	}
+	if c.Leader() != host(leader.Server) {
+		t.Errorf("Leader() = %s, want %s", c.Leader(), host(leader.Server))
+	}
+}
+
+func TestAllMastersUnreachable(t *testing.T) {
+	c := NewClient(&FrameworkConfig{MesosMasterServers: []string{unreachableMaster(t), unreachableMaster(t)}})
+	c.Retry.MaxAttempts = 1
+
+	if err := c.Accept(nil, nil, nil); err == nil {
+		t.Error("Accept() = nil, want the error of the unreachable masters")
+	}
+}
+
+func TestRedirectPingPong(t *testing.T) {
+	a := newRedirectingMaster(t, "")
+	b := newRedirectingMaster(t, host(a.Server))
+	a.leader.Store(host(b.Server))
+
+	c := NewClient(&FrameworkConfig{MesosMasterServers: []string{host(a.Server), host(b.Server)}})
+	c.Retry.MaxAttempts = 1
+
+	if err := c.Accept(nil, nil, nil); err == nil {
+		t.Fatal("Accept() = nil, want an error for the redirect loop")
+	}
+	// 2*len(masters)+1 attempts at most
+	if got := atomic.LoadInt32(&a.requests) + atomic.LoadInt32(&b.requests); got != 5 {
+		t.Errorf("masters got %d requests, want 5", got)
+	}
+}
+
+func TestDetectLeader(t *testing.T) {
+	follower := newRedirectingMaster(t, "leader.mesos:5050")
+	c := NewClient(&FrameworkConfig{MesosMasterServers: []string{unreachableMaster(t), host(follower.Server)}})
+
+	leader, err := c.DetectLeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leader != "leader.mesos:5050" || c.Leader() != leader {
+		t.Errorf("DetectLeader() = %s and Leader() = %s, want leader.mesos:5050", leader, c.Leader())
+	}
+}
+
+func TestDetectLeaderWithoutRedirect(t *testing.T) {
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer master.Close()
+	c := NewClient(&FrameworkConfig{MesosMasterServers: []string{host(master)}})
+
+	if _, err := c.DetectLeader(); err == nil {
+		t.Error("DetectLeader() = nil, want an error for a master without redirect")
+	}
+	if c.Leader() != "" {
+		t.Errorf("Leader() = %s, want no leader", c.Leader())
+	}
+}
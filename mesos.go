@@ -2,13 +2,12 @@ package mesosutil
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net"
 	"net/http"
-	"os"
 	"strings"
 
 	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
@@ -17,9 +16,6 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// Service include all the current vars and global config
-var config *FrameworkConfig
-
 // Marshaler to serialize Protobuf Message to JSON
 var marshaller = jsonpb.Marshaler{
 	EnumsAsInts: false,
@@ -27,80 +23,111 @@ var marshaller = jsonpb.Marshaler{
 	OrigName:    true,
 }
 
-// SetConfig set the global config
-func SetConfig(cfg *FrameworkConfig) {
-	config = cfg
-}
-
 // Call will send messages to mesos
-func Call(message *mesosproto.Call) error {
-	message.FrameworkID = config.FrameworkInfo.ID
-	body, _ := marshaller.MarshalToString(message)
+func (c *Client) Call(message *mesosproto.Call) error {
+	return c.CallContext(context.Background(), message)
+}
 
-	client := &http.Client{}
-	client.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+// CallContext will send messages to mesos until the context is done.
+// Idempotent calls will be retried according to the retry policy. An
+// attempt which run into the Timeout of the client is retried as well.
+func (c *Client) CallContext(ctx context.Context, message *mesosproto.Call) error {
+	// a client which could not be set up will never succeed
+	if c.err != nil {
+		return c.err
 	}
+	for attempt := 1; ; attempt++ {
+		err := c.call(ctx, message)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		// the caller is not done, so a deadline is the timeout of the attempt
+		retryable := IsRetryable(err) || errors.Is(err, context.DeadlineExceeded)
+		if !retryable || !c.Retry.shouldRetry(message.Type, attempt) {
+			return err
+		}
+		if !c.retry(ctx, message.Type, attempt, err) {
+			return err
+		}
+	}
+}
+
+// call send the message once to mesos
+func (c *Client) call(ctx context.Context, message *mesosproto.Call) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 
-	protocol := "https"
-	if !config.MesosSSL {
-		protocol = "http"
+	message.FrameworkID = c.FrameworkID()
+	body, err := c.Codec.Marshal(message)
+	if err != nil {
+		logrus.Error("Call Marshal: ", err)
+		return err
 	}
-	req, _ := http.NewRequest("POST", protocol+"://"+config.MesosMasterServer+"/api/v1/scheduler", bytes.NewBuffer([]byte(body)))
-	req.Close = true
-	req.SetBasicAuth(config.Username, config.Password)
-	req.Header.Set("Mesos-Stream-Id", config.MesosStreamID)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(req)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.url("/api/v1/scheduler"), bytes.NewBuffer(body))
+	req.Header.Set("Mesos-Stream-Id", c.StreamID())
+	req.Header.Set("Content-Type", c.Codec.ContentType())
+	res, err := c.do(req)
 
 	if err != nil {
 		logrus.Error("Call Message: ", err)
 		return err
 	}
 
-	defer res.Body.Close()
+	defer closeBody(res)
 
-	if res.StatusCode != 202 {
-		_, err := io.Copy(os.Stderr, res.Body)
-		if err != nil {
-			logrus.Error("Call Handling: ", err)
-		}
-		return fmt.Errorf("Error %d", res.StatusCode)
+	if res.StatusCode != http.StatusAccepted {
+		return newAPIError(res, message.Type)
 	}
 
 	return nil
 }
 
 // Revive will revive the mesos tasks to clean up
-func Revive() {
+func (c *Client) Revive() {
+	c.ReviveContext(context.Background())
+}
+
+// ReviveContext will revive the mesos tasks to clean up
+func (c *Client) ReviveContext(ctx context.Context) {
 	logrus.Debug("Revive Tasks")
 	revive := &mesosproto.Call{
 		Type: mesosproto.Call_REVIVE,
 	}
-	err := Call(revive)
+	err := c.CallContext(ctx, revive)
 	if err != nil {
 		logrus.Error("Call Revive: ", err)
 	}
 }
 
 // SuppressFramework if all Tasks are running, suppress framework offers
-func SuppressFramework() {
+func (c *Client) SuppressFramework() {
+	c.SuppressFrameworkContext(context.Background())
+}
+
+// SuppressFrameworkContext if all Tasks are running, suppress framework offers
+func (c *Client) SuppressFrameworkContext(ctx context.Context) {
 	logrus.Info("Framework Suppress")
 	suppress := &mesosproto.Call{
 		Type: mesosproto.Call_SUPPRESS,
 	}
-	err := Call(suppress)
+	err := c.CallContext(ctx, suppress)
 	if err != nil {
 		logrus.Error("Supress Framework Call: ")
 	}
 }
 
 // Kill a Task with the given taskID
-func Kill(taskID string, agentID string) error {
+func (c *Client) Kill(taskID string, agentID string) error {
+	return c.KillContext(context.Background(), taskID, agentID)
+}
+
+// KillContext kill a Task with the given taskID until the context is done
+func (c *Client) KillContext(ctx context.Context, taskID string, agentID string) error {
 
 	logrus.Debug("Kill task ", taskID)
 	// tell mesos to shutdonw the given task
-	err := Call(&mesosproto.Call{
+	err := c.CallContext(ctx, &mesosproto.Call{
 		Type: mesosproto.Call_KILL,
 		Kill: &mesosproto.Call_Kill{
 			TaskID: mesosproto.TaskID{
@@ -124,91 +151,70 @@ func DeclineOffer(offerIds []mesosproto.OfferID) *mesosproto.Call {
 	return decline
 }
 
-// GetOffer get out the offer for the mesos task
-func GetOffer(offers *mesosproto.Event_Offers, cmd Command) (mesosproto.Offer, []mesosproto.OfferID) {
-	var offerIds []mesosproto.OfferID
-	var offerret mesosproto.Offer
+// GetOffer get out the offer for the mesos task. All other offers of the
+// batch will be declined. It return the matched offer and its id, or an
+// empty offer if no offer has enough resources.
+func (c *Client) GetOffer(offers *mesosproto.Event_Offers, cmd Command) (mesosproto.Offer, []mesosproto.OfferID) {
+	return c.GetOfferContext(context.Background(), offers, cmd)
+}
 
-	for n, offer := range offers.Offers {
-		logrus.Debug("Got Offer From:", offer.GetHostname())
-		offerIds = append(offerIds, offer.ID)
+// GetOfferContext get out the offer for the mesos task
+func (c *Client) GetOfferContext(ctx context.Context, offers *mesosproto.Event_Offers, cmd Command) (mesosproto.Offer, []mesosproto.OfferID) {
+	var cmds []Command
+	if cmd.TaskName != "" {
+		cmds = append(cmds, cmd)
+	}
 
-		if cmd.TaskName == "" {
-			continue
-		}
+	plan := c.PlanOffers(offers, cmds)
+	err := c.declineAdaptive(ctx, plan.Decline, len(plan.Unmatched) > 0)
+	if err != nil {
+		logrus.WithField("func", "GetOffer").Error("Decline offers: ", err.Error())
+	}
 
-		// if the ressources of this offer does not matched what the command need, the skip
-		if !IsRessourceMatched(offer.Resources, cmd) {
-			logrus.Debug("Could not found any matched ressources, get next offer")
-			Call(DeclineOffer(offerIds))
-			continue
-		}
-		offerret = offers.Offers[n]
+	if len(plan.Matches) == 0 {
+		return mesosproto.Offer{}, nil
 	}
-	return offerret, offerIds
+	offer := plan.Matches[0].Offer
+	return offer, []mesosproto.OfferID{offer.ID}
 }
 
 // IsRessourceMatched - check if the ressources of the offer are matching the needs of the cmd
+// for the FrameworkRole of the default client. Without a default client,
+// reservations and the allocation role of the resources are ignored.
+//
+// Deprecated: use MatchResources with the role of the framework.
 func IsRessourceMatched(ressource []mesosproto.Resource, cmd Command) bool {
-	mem := false
-	cpu := false
-	ports := true
-
-	for _, v := range ressource {
-		if v.GetName() == "cpus" && v.Scalar.GetValue() >= cmd.CPU {
-			logrus.Debug("Matched Offer CPU")
-			cpu = true
-		}
-		if v.GetName() == "mem" && v.Scalar.GetValue() >= cmd.Memory {
-			logrus.Debug("Matched Offer Memory")
-			mem = true
-		}
-		if len(cmd.DockerPortMappings) > 0 {
-			if v.GetName() == "ports" {
-				for _, taskPort := range cmd.DockerPortMappings {
-					for _, portRange := range v.GetRanges().Range {
-						if taskPort.HostPort >= uint32(portRange.Begin) && taskPort.HostPort <= uint32(portRange.End) {
-							logrus.Debug("Matched Offer TaskPort: ", taskPort.HostPort)
-							logrus.Debug("Matched Offer RangePort: ", portRange)
-							ports = ports || true
-							break
-						}
-						ports = ports || false
-					}
-				}
-			}
-		}
+	role := ""
+	if defaultClient != nil && defaultClient.config != nil {
+		role = defaultClient.config.FrameworkRole
 	}
-
-	return mem && cpu && ports
+	_, ok := MatchResources(ressource, cmd, role)
+	return ok
 }
 
 // GetAgentInfo get information about the agent
-func GetAgentInfo(agentID string) MesosSlaves {
-	client := &http.Client{}
-	client.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
+func (c *Client) GetAgentInfo(agentID string) MesosSlaves {
+	return c.GetAgentInfoContext(context.Background(), agentID)
+}
 
-	protocol := "https"
-	if !config.MesosSSL {
-		protocol = "http"
-	}
-	req, _ := http.NewRequest("POST", protocol+"://"+config.MesosMasterServer+"/slaves/"+agentID, nil)
-	req.Close = true
-	req.SetBasicAuth(config.Username, config.Password)
-	req.Header.Set("Mesos-Stream-Id", config.MesosStreamID)
+// GetAgentInfoContext get information about the agent until the context is done
+func (c *Client) GetAgentInfoContext(ctx context.Context, agentID string) MesosSlaves {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.url("/slaves/"+agentID), nil)
+	req.Header.Set("Mesos-Stream-Id", c.StreamID())
 	req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(req)
+	res, err := c.do(req)
 
-	if res.StatusCode == http.StatusOK {
+	if err != nil {
+		logrus.WithField("func", "getAgentInfo").Error("Could not connect to agent: ", err.Error())
+		return MesosSlaves{}
+	}
 
-		if err != nil {
-			logrus.WithField("func", "getAgentInfo").Error("Could not connect to agent: ", err.Error())
-			return MesosSlaves{}
-		}
+	defer closeBody(res)
 
-		defer res.Body.Close()
+	if res.StatusCode == http.StatusOK {
 
 		var agent MesosAgent
 		err = json.NewDecoder(res.Body).Decode(&agent)
@@ -234,29 +240,25 @@ func GetAgentInfo(agentID string) MesosSlaves {
 }
 
 // GetNetworkInfo get network info of task
-func GetNetworkInfo(taskID string) []mesosproto.NetworkInfo {
-	client := &http.Client{}
-	// #nosec G402
-	client.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
+func (c *Client) GetNetworkInfo(taskID string) []mesosproto.NetworkInfo {
+	return c.GetNetworkInfoContext(context.Background(), taskID)
+}
 
-	protocol := "https"
-	if !config.MesosSSL {
-		protocol = "http"
-	}
-	req, _ := http.NewRequest("POST", protocol+"://"+config.MesosMasterServer+"/tasks/?task_id="+taskID+"&framework_id="+config.FrameworkInfo.ID.GetValue(), nil)
-	req.Close = true
-	req.SetBasicAuth(config.Username, config.Password)
+// GetNetworkInfoContext get network info of task until the context is done
+func (c *Client) GetNetworkInfoContext(ctx context.Context, taskID string) []mesosproto.NetworkInfo {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.url("/tasks/?task_id="+taskID+"&framework_id="+c.FrameworkID().GetValue()), nil)
 	req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(req)
+	res, err := c.do(req)
 
 	if err != nil {
 		logrus.WithField("func", "getNetworkInfo").Error("Could not connect to agent: ", err.Error())
 		return []mesosproto.NetworkInfo{}
 	}
 
-	defer res.Body.Close()
+	defer closeBody(res)
 
 	var task MesosTasks
 	err = json.NewDecoder(res.Body).Decode(&task)
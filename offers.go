@@ -0,0 +1,171 @@
+package mesosutil
+
+import (
+	"context"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OfferMatch is a command which will be launched on an offer
+type OfferMatch struct {
+	Offer   mesosproto.Offer
+	Command Command
+	// Resources of the offer the command will use
+	Resources []mesosproto.Resource
+}
+
+// OfferPlan say which command goes to which offer and which offers have to
+// be declined. Every offer is either part of one match or declined.
+type OfferPlan struct {
+	Matches []OfferMatch
+	Decline []mesosproto.OfferID
+	// Unmatched are the commands without a matching offer
+	Unmatched []Command
+}
+
+// PlanOffers match the pending commands with a batch of offers. Every
+// command get the first offer with enough resources, every offer is used
+// for one command at most. Only resources of the FrameworkRole are used,
+// and the agent has to match the constraints of the command. The planned
+// command get the attributes of the agent.
+func (c *Client) PlanOffers(offers *mesosproto.Event_Offers, cmds []Command) OfferPlan {
+	var plan OfferPlan
+	used := make([]bool, len(offers.GetOffers()))
+	placed := c.placedAttributes()
+
+	attributes := make([]map[string]string, len(offers.GetOffers()))
+	for n, offer := range offers.GetOffers() {
+		attributes[n] = OfferAttributes(offer)
+	}
+
+	for _, cmd := range cmds {
+		// the constraints are checked against all unused offers with
+		// enough resources
+		var available []map[string]string
+		for n, offer := range offers.GetOffers() {
+			if used[n] {
+				continue
+			}
+			if _, ok := MatchResources(offer.Resources, cmd, c.config.FrameworkRole); ok {
+				available = append(available, attributes[n])
+			}
+		}
+
+		matched := false
+		for n, offer := range offers.GetOffers() {
+			if used[n] {
+				continue
+			}
+			if !matchConstraints(attributes[n], cmd, placed[cmd.TaskName], available) {
+				continue
+			}
+			planned, resources, ok := allocateResources(offer.Resources, cmd, c.config.FrameworkRole)
+			if !ok {
+				continue
+			}
+			logrus.WithField("func", "PlanOffers").Debug("Matched task ", cmd.TaskName, " with offer from ", offer.GetHostname())
+			planned.AgentAttributes = attributes[n]
+			placed[cmd.TaskName] = append(placed[cmd.TaskName], attributes[n])
+			plan.Matches = append(plan.Matches, OfferMatch{Offer: offer, Command: planned, Resources: resources})
+			used[n] = true
+			matched = true
+			break
+		}
+		if !matched {
+			logrus.WithField("func", "PlanOffers").Debug("Could not found any matched ressources for task ", cmd.TaskName)
+			plan.Unmatched = append(plan.Unmatched, cmd)
+		}
+	}
+
+	for n, offer := range offers.GetOffers() {
+		if !used[n] {
+			plan.Decline = append(plan.Decline, offer.ID)
+		}
+	}
+
+	return plan
+}
+
+// ExecutePlan launch the matched commands and decline the unused offers
+// once. All commands of one offer are launched with one ACCEPT call. It
+// return the TaskInfos of the launched tasks.
+func (c *Client) ExecutePlan(plan OfferPlan, filters *mesosproto.Filters) ([]mesosproto.TaskInfo, error) {
+	return c.ExecutePlanContext(context.Background(), plan, filters)
+}
+
+// ExecutePlanContext execute the offer plan until the context is done
+func (c *Client) ExecutePlanContext(ctx context.Context, plan OfferPlan, filters *mesosproto.Filters) ([]mesosproto.TaskInfo, error) {
+	var launched []mesosproto.TaskInfo
+	var lastErr error
+
+	// group the tasks by offer, in the order of the plan
+	var offerIds, unfit []mesosproto.OfferID
+	tasks := make(map[string][]mesosproto.TaskInfo)
+	agents := make(map[string]mesosproto.AgentID)
+	for _, match := range plan.Matches {
+		task, err := c.matchTaskInfo(match)
+		if err != nil {
+			logrus.WithField("func", "ExecutePlan").Error("Task ", match.Command.TaskName, " does not fit on offer ", match.Offer.ID.Value)
+			unfit = append(unfit, match.Offer.ID)
+			lastErr = err
+			continue
+		}
+
+		id := match.Offer.ID.Value
+		if _, ok := tasks[id]; !ok {
+			offerIds = append(offerIds, match.Offer.ID)
+			agents[id] = match.Offer.AgentID
+		}
+		tasks[id] = append(tasks[id], task)
+	}
+
+	for _, offerID := range offerIds {
+		offerTasks := tasks[offerID.Value]
+		logrus.WithField("func", "ExecutePlan").Debug("Launch ", len(offerTasks), " tasks on offer ", offerID.Value)
+		err := c.AcceptAgentContext(ctx, agents[offerID.Value], []mesosproto.OfferID{offerID}, []mesosproto.Offer_Operation{LaunchOperation(offerTasks...)}, filters)
+		if err != nil {
+			logrus.WithField("func", "ExecutePlan").Error("Launch tasks on offer ", offerID.Value, ": ", err.Error())
+			lastErr = err
+			continue
+		}
+		launched = append(launched, offerTasks...)
+	}
+
+	// offers without any task which fit on them are declined too
+	decline := plan.Decline
+	for _, offerID := range unfit {
+		if _, ok := tasks[offerID.Value]; !ok {
+			decline = append(decline, offerID)
+			tasks[offerID.Value] = nil
+		}
+	}
+
+	err := c.declineAdaptive(ctx, decline, len(plan.Unmatched) > 0 || len(unfit) > 0)
+	if err != nil {
+		logrus.WithField("func", "ExecutePlan").Error("Decline offers: ", err.Error())
+		lastErr = err
+	}
+
+	return launched, lastErr
+}
+
+// matchTaskInfo build the TaskInfo of a match. Matches without planned
+// resources get them out of the offer.
+func (c *Client) matchTaskInfo(match OfferMatch) (mesosproto.TaskInfo, error) {
+	if match.Resources == nil {
+		return c.PrepareTaskInfo(match.Command, match.Offer)
+	}
+	return c.prepareTaskInfo(match.Command, match.Offer, match.Resources), nil
+}
+
+// PlanOffers match the commands with the offers with the default client
+func PlanOffers(offers *mesosproto.Event_Offers, cmds []Command) OfferPlan {
+	return defaultClient.PlanOffers(offers, cmds)
+}
+
+// ExecutePlan execute the offer plan with the default client
+func ExecutePlan(plan OfferPlan, filters *mesosproto.Filters) ([]mesosproto.TaskInfo, error) {
+	return defaultClient.ExecutePlan(plan, filters)
+}
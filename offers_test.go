@@ -0,0 +1,80 @@
+package mesosutil
+
+import (
+	"testing"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+// testOffer create an offer with cpus, mem and ports
+func testOffer(id string, hostname string, cpus float64, mem float64, ports ...mesosproto.Value_Range) mesosproto.Offer {
+	resources := []mesosproto.Resource{
+		scalarResource("cpus", cpus),
+		scalarResource("mem", mem),
+	}
+	if len(ports) > 0 {
+		resources = append(resources, rangesResource("ports", ports))
+	}
+	return mesosproto.Offer{
+		ID:        mesosproto.OfferID{Value: id},
+		AgentID:   mesosproto.AgentID{Value: "agent-" + id},
+		Hostname:  hostname,
+		Resources: resources,
+	}
+}
+
+func TestPlanOffersDeclineUnusedOnce(t *testing.T) {
+	c := NewClient(&FrameworkConfig{})
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{
+		testOffer("1", "small", 0.5, 256),
+		testOffer("2", "big", 4, 4096),
+		testOffer("3", "big2", 4, 4096),
+	}}
+
+	plan := c.PlanOffers(offers, []Command{
+		{TaskName: "a", CPU: 1, Memory: 512},
+		{TaskName: "b", CPU: 8, Memory: 512},
+	})
+
+	if len(plan.Matches) != 1 || plan.Matches[0].Offer.ID.Value != "2" {
+		t.Fatalf("matches = %v, want task a on offer 2", plan.Matches)
+	}
+	if len(plan.Unmatched) != 1 || plan.Unmatched[0].TaskName != "b" {
+		t.Errorf("unmatched = %v, want task b", plan.Unmatched)
+	}
+	if len(plan.Decline) != 2 || plan.Decline[0].Value != "1" || plan.Decline[1].Value != "3" {
+		t.Errorf("decline = %v, want offers 1 and 3", plan.Decline)
+	}
+}
+
+func TestGetOfferKeepRefuseShortForUnmatchedCommand(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{testOffer("1", "small", 1, 256)}}
+
+	for i := 0; i < 3; i++ {
+		offer, ids := c.GetOffer(offers, Command{TaskName: "big", CPU: 8, Memory: 512})
+		if ids != nil || offer.ID.Value != "" {
+			t.Fatalf("GetOffer() matched offer %s", offer.ID.Value)
+		}
+	}
+
+	declines := master.received(mesosproto.Call_DECLINE)
+	if len(declines) != 3 {
+		t.Fatalf("got %d DECLINE calls, want 3", len(declines))
+	}
+	for _, decline := range declines {
+		if got := decline.Decline.Filters.GetRefuseSeconds(); got != c.Refuse.Min.Seconds() {
+			t.Errorf("refuse_seconds = %v while a command is waiting, want %v", got, c.Refuse.Min.Seconds())
+		}
+	}
+
+	// without pending work the refuse duration grow
+	c.DeclineAdaptive([]mesosproto.OfferID{{Value: "1"}})
+	c.DeclineAdaptive([]mesosproto.OfferID{{Value: "1"}})
+	declines = master.received(mesosproto.Call_DECLINE)
+	if got := declines[len(declines)-1].Decline.Filters.GetRefuseSeconds(); got != (20 * time.Second).Seconds() {
+		t.Errorf("refuse_seconds = %v without pending work, want 20", got)
+	}
+}
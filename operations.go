@@ -0,0 +1,250 @@
+package mesosutil
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TrackedOperation is an offer operation with an id and the last state
+// mesos reported for it
+type TrackedOperation struct {
+	ID      string
+	Type    mesosproto.Offer_Operation_Type
+	AgentID *mesosproto.AgentID
+	// State is OPERATION_PENDING until mesos reported an other state
+	State   mesosproto.OperationState
+	Message string
+	Updated time.Time
+}
+
+// Terminal check if the operation is finished, failed or dropped
+func (op TrackedOperation) Terminal() bool {
+	switch op.State {
+	case mesosproto.OPERATION_FINISHED, mesosproto.OPERATION_FAILED, mesosproto.OPERATION_ERROR,
+		mesosproto.OPERATION_DROPPED, mesosproto.OPERATION_GONE_BY_OPERATOR:
+		return true
+	}
+	return false
+}
+
+// OperationTracker give the offer operations of the client an id and
+// follow their state by the UPDATE_OPERATION_STATUS events. It is used by
+// the client if it is set as Client.Operations.
+type OperationTracker struct {
+	client *Client
+
+	mu         sync.Mutex
+	seq        uint64
+	operations map[string]*TrackedOperation
+}
+
+// NewOperationTracker create an operation tracker of the client
+func (c *Client) NewOperationTracker() *OperationTracker {
+	return &OperationTracker{
+		client:     c,
+		operations: make(map[string]*TrackedOperation),
+	}
+}
+
+// assign give every operation without id an id. LAUNCH operations do not
+// support ids and are not changed.
+func (t *OperationTracker) assign(operations []mesosproto.Offer_Operation) []mesosproto.Offer_Operation {
+	assigned := make([]mesosproto.Offer_Operation, len(operations))
+	copy(assigned, operations)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for n := range assigned {
+		op := &assigned[n]
+		if op.ID != nil || op.Type == mesosproto.Offer_Operation_LAUNCH || op.Type == mesosproto.Offer_Operation_LAUNCH_GROUP {
+			continue
+		}
+		t.seq++
+		op.ID = &mesosproto.OperationID{
+			Value: "operation." + strconv.FormatInt(time.Now().UnixNano(), 10) + "." + strconv.FormatUint(t.seq, 10),
+		}
+	}
+	return assigned
+}
+
+// track remember the sent operations with an id as pending on the agent.
+// agentID is nil if the agent of the offers is unknown.
+func (t *OperationTracker) track(operations []mesosproto.Offer_Operation, agentID *mesosproto.AgentID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, op := range operations {
+		if op.ID == nil {
+			continue
+		}
+		// the status update can be faster than the response of the call
+		if tracked, ok := t.operations[op.ID.Value]; ok {
+			if tracked.AgentID == nil {
+				tracked.AgentID = agentID
+			}
+			continue
+		}
+		t.operations[op.ID.Value] = &TrackedOperation{
+			ID:      op.ID.Value,
+			Type:    op.Type,
+			AgentID: agentID,
+			State:   mesosproto.OPERATION_PENDING,
+			Updated: time.Now(),
+		}
+	}
+}
+
+// Update set the state of the operation of the status
+func (t *OperationTracker) Update(status mesosproto.OperationStatus) {
+	if status.OperationID == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	op, ok := t.operations[status.OperationID.Value]
+	if !ok {
+		op = &TrackedOperation{ID: status.OperationID.Value}
+		t.operations[op.ID] = op
+	}
+	op.State = status.State
+	op.Message = status.GetMessage()
+	op.Updated = time.Now()
+	if status.AgentID != nil {
+		op.AgentID = status.AgentID
+	}
+	logrus.WithField("func", "OperationTracker.Update").Debug("Operation ", op.ID, " is ", op.State.String())
+}
+
+// Middleware update the operations by the UPDATE_OPERATION_STATUS events
+// and acknowledge the status after the handler returned successfully. It
+// has to be registered at the dispatcher of the client.
+func (t *OperationTracker) Middleware(next EventHandler) EventHandler {
+	return func(event *mesosproto.Event) error {
+		if event.Type != mesosproto.Event_UPDATE_OPERATION_STATUS || event.UpdateOperationStatus == nil {
+			return next(event)
+		}
+		t.Update(event.UpdateOperationStatus.Status)
+		err := next(event)
+		if err != nil {
+			return err
+		}
+		return t.client.AcknowledgeOperation(event.UpdateOperationStatus.Status)
+	}
+}
+
+// Operation return the tracked operation with the id
+func (t *OperationTracker) Operation(id string) (TrackedOperation, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	op, ok := t.operations[id]
+	if !ok {
+		return TrackedOperation{}, false
+	}
+	return *op, true
+}
+
+// Operations return all tracked operations, sorted by id
+func (t *OperationTracker) Operations() []TrackedOperation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	operations := make([]TrackedOperation, 0, len(t.operations))
+	for _, op := range t.operations {
+		operations = append(operations, *op)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].ID < operations[j].ID
+	})
+	return operations
+}
+
+// Pending return the operations which did not reach a terminal state yet
+func (t *OperationTracker) Pending() []TrackedOperation {
+	var pending []TrackedOperation
+	for _, op := range t.Operations() {
+		if !op.Terminal() {
+			pending = append(pending, op)
+		}
+	}
+	return pending
+}
+
+// Forget remove the operation from the tracker
+func (t *OperationTracker) Forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.operations, id)
+}
+
+// Reconcile ask mesos for the latest state of all pending operations
+func (t *OperationTracker) Reconcile(ctx context.Context) error {
+	var operations []mesosproto.Call_ReconcileOperations_Operation
+	for _, op := range t.Pending() {
+		operations = append(operations, mesosproto.Call_ReconcileOperations_Operation{
+			OperationID: mesosproto.OperationID{Value: op.ID},
+			AgentID:     op.AgentID,
+		})
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+	return t.client.ReconcileOperationsContext(ctx, operations)
+}
+
+// AcknowledgeOperation acknowledge the status update of an operation. Mesos
+// resend the update until it is acknowledged. Updates without uuid do not
+// need an acknowledgement.
+func (c *Client) AcknowledgeOperation(status mesosproto.OperationStatus) error {
+	return c.AcknowledgeOperationContext(context.Background(), status)
+}
+
+// AcknowledgeOperationContext acknowledge the status update of an operation until the context is done
+func (c *Client) AcknowledgeOperationContext(ctx context.Context, status mesosproto.OperationStatus) error {
+	if status.UUID == nil || status.OperationID == nil || (status.AgentID == nil && status.ResourceProviderID == nil) {
+		return nil
+	}
+
+	logrus.WithField("func", "AcknowledgeOperation").Debug("Acknowledge status of operation ", status.OperationID.Value)
+	return c.CallContext(ctx, &mesosproto.Call{
+		Type: mesosproto.Call_ACKNOWLEDGE_OPERATION_STATUS,
+		AcknowledgeOperationStatus: &mesosproto.Call_AcknowledgeOperationStatus{
+			AgentID:            status.AgentID,
+			ResourceProviderID: status.ResourceProviderID,
+			UUID:               status.UUID.Value,
+			OperationID:        *status.OperationID,
+		},
+	})
+}
+
+// ReconcileOperations ask mesos for the latest state of the operations. An
+// empty list reconcile all operations of the framework.
+func (c *Client) ReconcileOperations(operations []mesosproto.Call_ReconcileOperations_Operation) error {
+	return c.ReconcileOperationsContext(context.Background(), operations)
+}
+
+// ReconcileOperationsContext reconcile the operations until the context is done
+func (c *Client) ReconcileOperationsContext(ctx context.Context, operations []mesosproto.Call_ReconcileOperations_Operation) error {
+	logrus.WithField("func", "ReconcileOperations").Debug("Reconcile ", len(operations), " operations")
+	return c.CallContext(ctx, &mesosproto.Call{
+		Type: mesosproto.Call_RECONCILE_OPERATIONS,
+		ReconcileOperations: &mesosproto.Call_ReconcileOperations{
+			Operations: operations,
+		},
+	})
+}
+
+// AcknowledgeOperation acknowledge the operation status with the default client
+func AcknowledgeOperation(status mesosproto.OperationStatus) error {
+	return defaultClient.AcknowledgeOperation(status)
+}
+
+// ReconcileOperations reconcile the operations with the default client
+func ReconcileOperations(operations []mesosproto.Call_ReconcileOperations_Operation) error {
+	return defaultClient.ReconcileOperations(operations)
+}
@@ -0,0 +1,89 @@
+package mesosutil
+
+import (
+	"context"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+func TestReconcileOperationsWithAgent(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+	c.Operations = c.NewOperationTracker()
+
+	offer := testOffer("1", "agent1", 4, 4096)
+	if _, err := c.Reserve(offer, Command{TaskName: "db", CPU: 1, Memory: 128}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	accept := master.received(mesosproto.Call_ACCEPT)
+	if len(accept) != 1 || accept[0].Accept.Operations[0].ID == nil {
+		t.Fatalf("got %v, want one ACCEPT with an operation id", accept)
+	}
+	id := accept[0].Accept.Operations[0].ID.Value
+
+	if err := c.Operations.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reconcile := master.received(mesosproto.Call_RECONCILE_OPERATIONS)
+	if len(reconcile) != 1 {
+		t.Fatalf("got %d RECONCILE_OPERATIONS calls, want 1", len(reconcile))
+	}
+	ops := reconcile[0].ReconcileOperations.Operations
+	if len(ops) != 1 || ops[0].OperationID.Value != id {
+		t.Fatalf("got operations %v, want %s", ops, id)
+	}
+	if ops[0].AgentID == nil || ops[0].AgentID.Value != offer.AgentID.Value {
+		t.Errorf("got agent %v, want %s", ops[0].AgentID, offer.AgentID.Value)
+	}
+}
+
+func TestOperationMiddlewareAcknowledge(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+	c.Operations = c.NewOperationTracker()
+
+	offer := testOffer("1", "agent1", 4, 4096)
+	if err := c.AcceptAgent(offer.AgentID, []mesosproto.OfferID{offer.ID}, []mesosproto.Offer_Operation{ReserveOperation(scalarResource("cpus", 1))}, nil); err != nil {
+		t.Fatal(err)
+	}
+	pending := c.Operations.Pending()
+	if len(pending) != 1 || pending[0].State != mesosproto.OPERATION_PENDING {
+		t.Fatalf("got pending operations %v, want one", pending)
+	}
+
+	handled := 0
+	handler := c.Operations.Middleware(func(event *mesosproto.Event) error {
+		handled++
+		return nil
+	})
+	err := handler(&mesosproto.Event{
+		Type: mesosproto.Event_UPDATE_OPERATION_STATUS,
+		UpdateOperationStatus: &mesosproto.Event_UpdateOperationStatus{
+			Status: mesosproto.OperationStatus{
+				OperationID: &mesosproto.OperationID{Value: pending[0].ID},
+				State:       mesosproto.OPERATION_FINISHED,
+				AgentID:     &offer.AgentID,
+				UUID:        &mesosproto.UUID{Value: []byte("uuid-1")},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if handled != 1 {
+		t.Errorf("handler called %d times, want 1", handled)
+	}
+	if op, _ := c.Operations.Operation(pending[0].ID); op.State != mesosproto.OPERATION_FINISHED {
+		t.Errorf("got state %s, want OPERATION_FINISHED", op.State)
+	}
+	if len(c.Operations.Pending()) != 0 {
+		t.Errorf("got pending operations %v, want none", c.Operations.Pending())
+	}
+	ack := master.received(mesosproto.Call_ACKNOWLEDGE_OPERATION_STATUS)
+	if len(ack) != 1 || ack[0].AcknowledgeOperationStatus.OperationID.Value != pending[0].ID {
+		t.Errorf("got acknowledgements %v, want one of %s", ack, pending[0].ID)
+	}
+}
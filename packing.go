@@ -0,0 +1,148 @@
+package mesosutil
+
+import (
+	"strconv"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PackingStrategy select the offer of a command if several offers match
+type PackingStrategy int
+
+const (
+	// FirstFit place the command on the first offer with enough resources
+	FirstFit PackingStrategy = iota
+	// BinPack place the command on the offer with the least remaining cpus,
+	// to fill up agents before the next one is used
+	BinPack
+	// Spread place the command on the offer with the fewest placed tasks,
+	// to distribute the tasks over all agents
+	Spread
+)
+
+// offerSlot is an offer with the resources which are not used by the
+// planned tasks yet
+type offerSlot struct {
+	offer      mesosproto.Offer
+	attributes map[string]string
+	remaining  []mesosproto.Resource
+	tasks      int
+}
+
+// PackOffers place the commands onto the batch of offers. Several commands
+// can be placed on one offer, the used cpus, memory, disk and ports are
+// subtracted from the offer. A command with more than one Instances is
+// placed Instances times. Only resources of the FrameworkRole are used,
+// and the agent has to match the constraints of the command.
+func (c *Client) PackOffers(offers *mesosproto.Event_Offers, cmds []Command, strategy PackingStrategy) OfferPlan {
+	role := c.config.FrameworkRole
+	placed := c.placedAttributes()
+	var plan OfferPlan
+
+	slots := make([]*offerSlot, len(offers.GetOffers()))
+	for n, offer := range offers.GetOffers() {
+		slots[n] = &offerSlot{
+			offer:      offer,
+			attributes: OfferAttributes(offer),
+			remaining:  copyResources(offer.Resources),
+		}
+	}
+
+	for _, cmd := range expandInstances(cmds) {
+		slot := selectSlot(slots, cmd, role, strategy, placed[cmd.TaskName])
+		if slot == nil {
+			logrus.WithField("func", "PackOffers").Debug("Could not found any matched ressources for task ", cmd.TaskName)
+			plan.Unmatched = append(plan.Unmatched, cmd)
+			continue
+		}
+
+		logrus.WithField("func", "PackOffers").Debug("Place task ", cmd.TaskName, " on offer from ", slot.offer.GetHostname())
+		planned, resources, _ := allocateResources(slot.remaining, cmd, role)
+		subtractResources(slot.remaining, resources)
+		slot.tasks++
+		planned.AgentAttributes = slot.attributes
+		placed[cmd.TaskName] = append(placed[cmd.TaskName], slot.attributes)
+		plan.Matches = append(plan.Matches, OfferMatch{Offer: slot.offer, Command: planned, Resources: resources})
+	}
+
+	for _, slot := range slots {
+		if slot.tasks == 0 {
+			plan.Decline = append(plan.Decline, slot.offer.ID)
+		}
+	}
+
+	return plan
+}
+
+// selectSlot return the offer of the command by the strategy, or nil if
+// no offer has enough remaining resources or match the constraints
+func selectSlot(slots []*offerSlot, cmd Command, role string, strategy PackingStrategy, placed []map[string]string) *offerSlot {
+	// the constraints are checked against all offers with enough resources
+	var candidates []*offerSlot
+	var available []map[string]string
+	for _, slot := range slots {
+		if _, ok := MatchResources(slot.remaining, cmd, role); ok {
+			candidates = append(candidates, slot)
+			available = append(available, slot.attributes)
+		}
+	}
+
+	var selected *offerSlot
+	for _, slot := range candidates {
+		if !matchConstraints(slot.attributes, cmd, placed, available) {
+			continue
+		}
+		if selected == nil {
+			selected = slot
+			if strategy == FirstFit {
+				return selected
+			}
+			continue
+		}
+
+		switch strategy {
+		case BinPack:
+			if scalarValue(slot.remaining, "cpus") < scalarValue(selected.remaining, "cpus") {
+				selected = slot
+			}
+		case Spread:
+			if slot.tasks < selected.tasks ||
+				(slot.tasks == selected.tasks && scalarValue(slot.remaining, "cpus") > scalarValue(selected.remaining, "cpus")) {
+				selected = slot
+			}
+		}
+	}
+	return selected
+}
+
+// PackOffers place the commands onto the offers with the default client
+func PackOffers(offers *mesosproto.Event_Offers, cmds []Command, strategy PackingStrategy) OfferPlan {
+	return defaultClient.PackOffers(offers, cmds, strategy)
+}
+
+// expandInstances return one command for every instance. The task ids of
+// the instances get the number of the instance as suffix, so they are
+// unique even if the command has no TaskID.
+func expandInstances(cmds []Command) []Command {
+	var expanded []Command
+	for _, cmd := range cmds {
+		if cmd.Instances <= 1 {
+			expanded = append(expanded, cmd)
+			continue
+		}
+		taskID := cmd.TaskID
+		if taskID == "" {
+			taskID = cmd.TaskName + "." + strconv.FormatInt(time.Now().UnixNano(), 10)
+		}
+		for i := 0; i < cmd.Instances; i++ {
+			instance := cmd
+			instance.Instances = 1
+			instance.TaskID = taskID + "." + strconv.Itoa(i)
+			expanded = append(expanded, instance)
+		}
+	}
+	return expanded
+}
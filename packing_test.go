@@ -0,0 +1,158 @@
+package mesosutil
+
+import (
+	"strings"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+// placement return the number of planned tasks per offer id
+func placement(plan OfferPlan) map[string]int {
+	placed := make(map[string]int)
+	for _, match := range plan.Matches {
+		placed[match.Offer.ID.Value]++
+	}
+	return placed
+}
+
+func TestPackOffersStrategy(t *testing.T) {
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{
+		testOffer("1", "agent1", 4, 4096),
+		testOffer("2", "agent2", 2, 4096),
+		testOffer("3", "agent3", 3, 4096),
+	}}
+	cmd := Command{TaskName: "web", TaskID: "web", CPU: 1, Memory: 128, Instances: 3}
+
+	tests := []struct {
+		name     string
+		strategy PackingStrategy
+		want     map[string]int
+		decline  int
+	}{
+		// the first offer has room for all instances
+		{"first fit", FirstFit, map[string]int{"1": 3}, 2},
+		// fill the agent with the fewest cpus first
+		{"bin pack", BinPack, map[string]int{"2": 2, "3": 1}, 1},
+		// one instance per agent
+		{"spread", Spread, map[string]int{"1": 1, "2": 1, "3": 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := NewClient(&FrameworkConfig{}).PackOffers(offers, []Command{cmd}, tt.strategy)
+			got := placement(plan)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got placement %v, want %v", got, tt.want)
+			}
+			for id, count := range tt.want {
+				if got[id] != count {
+					t.Errorf("got placement %v, want %v", got, tt.want)
+				}
+			}
+			if len(plan.Decline) != tt.decline {
+				t.Errorf("got %d declined offers, want %d", len(plan.Decline), tt.decline)
+			}
+		})
+	}
+}
+
+func TestPackOffersSubtractResources(t *testing.T) {
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{
+		testOffer("1", "agent1", 2, 1024),
+	}}
+	cmds := []Command{
+		{TaskName: "web", CPU: 1, Memory: 512, Instances: 2},
+		{TaskName: "db", CPU: 0.5, Memory: 128},
+	}
+
+	plan := NewClient(&FrameworkConfig{}).PackOffers(offers, cmds, BinPack)
+	if len(plan.Matches) != 2 {
+		t.Fatalf("got %d matches, want 2", len(plan.Matches))
+	}
+	if len(plan.Unmatched) != 1 || plan.Unmatched[0].TaskName != "db" {
+		t.Errorf("got unmatched %v, want db which does not fit anymore", plan.Unmatched)
+	}
+}
+
+func TestPackOffersAssignDistinctPorts(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+	offers := &mesosproto.Event_Offers{Offers: []mesosproto.Offer{
+		testOffer("1", "agent1", 4, 4096, mesosproto.Value_Range{Begin: 31000, End: 31001}),
+	}}
+	cmd := Command{
+		TaskName:  "web",
+		TaskID:    "web",
+		CPU:       1,
+		Memory:    128,
+		Instances: 3,
+		DockerPortMappings: []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(0, 80, "tcp"),
+		},
+	}
+
+	plan := c.PackOffers(offers, []Command{cmd}, BinPack)
+	if len(plan.Matches) != 2 || len(plan.Unmatched) != 1 {
+		t.Fatalf("got %d matches and %d unmatched, want 2 and 1 without a free port", len(plan.Matches), len(plan.Unmatched))
+	}
+
+	tasks, err := c.ExecutePlan(plan, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].TaskID.Value == tasks[1].TaskID.Value {
+		t.Fatalf("got tasks %v, want two instances", tasks)
+	}
+	ports := make(map[uint64]bool)
+	for _, task := range tasks {
+		for _, res := range task.Resources {
+			if res.GetName() == "ports" {
+				ports[res.Ranges.Range[0].Begin] = true
+			}
+		}
+	}
+	if !ports[31000] || !ports[31001] {
+		t.Errorf("got host ports %v, want 31000 and 31001", ports)
+	}
+
+	accept := master.received(mesosproto.Call_ACCEPT)
+	if len(accept) != 1 || len(accept[0].Accept.Operations[0].Launch.TaskInfos) != 2 {
+		t.Errorf("got %d ACCEPT calls, want one with both tasks", len(accept))
+	}
+}
+
+func TestExpandInstances(t *testing.T) {
+	expanded := expandInstances([]Command{
+		{TaskName: "web", TaskID: "web", Instances: 2},
+		{TaskName: "db", TaskID: "db"},
+	})
+
+	var ids []string
+	for _, cmd := range expanded {
+		ids = append(ids, cmd.TaskID)
+		if cmd.Instances > 1 {
+			t.Errorf("instance %s has %d instances, want 1", cmd.TaskID, cmd.Instances)
+		}
+	}
+	if len(ids) != 3 || ids[0] != "web.0" || ids[1] != "web.1" || ids[2] != "db" {
+		t.Errorf("got task ids %v, want web.0, web.1 and db", ids)
+	}
+}
+
+func TestExpandInstancesWithoutTaskID(t *testing.T) {
+	expanded := expandInstances([]Command{{TaskName: "web", Instances: 100}})
+
+	ids := make(map[string]bool)
+	for _, cmd := range expanded {
+		if !strings.HasPrefix(cmd.TaskID, "web.") {
+			t.Errorf("got task id %s, want the prefix web.", cmd.TaskID)
+		}
+		ids[cmd.TaskID] = true
+	}
+	if len(ids) != 100 {
+		t.Errorf("got %d different task ids for 100 instances", len(ids))
+	}
+	if !strings.HasSuffix(expanded[1].TaskID, ".1") {
+		t.Errorf("got task id %s, want the instance number as suffix", expanded[1].TaskID)
+	}
+}
@@ -0,0 +1,123 @@
+package mesosutil
+
+import (
+	"strings"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MatchPorts check if every host port of the port mappings is inside the
+// offered port ranges. A host port 0 get any free port of the offer. It
+// return the port mappings with the assigned host ports, and false if the
+// offer has not all the ports. The tcp and udp mapping of one container
+// port with host port 0 get the same host port.
+func MatchPorts(ressource []mesosproto.Resource, mappings []mesosproto.ContainerInfo_DockerInfo_PortMapping) ([]mesosproto.ContainerInfo_DockerInfo_PortMapping, bool) {
+	if len(mappings) == 0 {
+		return mappings, true
+	}
+
+	var ranges []mesosproto.Value_Range
+	for _, v := range ressource {
+		if v.GetName() == "ports" && v.Ranges != nil {
+			ranges = append(ranges, v.Ranges.Range...)
+		}
+	}
+
+	assigned := make([]mesosproto.ContainerInfo_DockerInfo_PortMapping, len(mappings))
+	copy(assigned, mappings)
+	used := make(map[uint64]bool)
+
+	// the fixed host ports first, so they are not taken by a random port.
+	// The same host port can be mapped several times, e.g. for tcp and udp.
+	for _, port := range assigned {
+		if port.HostPort == 0 {
+			continue
+		}
+		if !inRanges(ranges, uint64(port.HostPort)) {
+			logrus.Debug("Offer has not the TaskPort: ", port.HostPort)
+			return mappings, false
+		}
+		used[uint64(port.HostPort)] = true
+	}
+
+	for n := range assigned {
+		if assigned[n].HostPort != 0 {
+			continue
+		}
+		if port := sharedPort(mappings, assigned, n); port != 0 {
+			assigned[n].HostPort = port
+			continue
+		}
+		port, ok := freePort(ranges, used)
+		if !ok {
+			logrus.Debug("Offer has not enough free ports")
+			return mappings, false
+		}
+		logrus.Debug("Assigned Offer TaskPort: ", port)
+		used[port] = true
+		assigned[n].HostPort = uint32(port)
+	}
+
+	return assigned, true
+}
+
+// AssignPorts return the command with the host ports it get on the offer
+func AssignPorts(ressource []mesosproto.Resource, cmd Command) (Command, bool) {
+	mappings, ok := MatchPorts(ressource, cmd.DockerPortMappings)
+	if !ok {
+		return cmd, false
+	}
+	cmd.DockerPortMappings = mappings
+	return cmd, true
+}
+
+// sharedPort return the host port assigned to an earlier mapping of the
+// same container port with an other protocol, so tcp and udp of one
+// container port get the same host port. It return 0 if there is none.
+func sharedPort(mappings []mesosproto.ContainerInfo_DockerInfo_PortMapping, assigned []mesosproto.ContainerInfo_DockerInfo_PortMapping, n int) uint32 {
+	for i := 0; i < n; i++ {
+		if mappings[i].HostPort == 0 && mappings[i].ContainerPort == mappings[n].ContainerPort &&
+			portProtocol(mappings[i]) != portProtocol(mappings[n]) {
+			return assigned[i].HostPort
+		}
+	}
+	return 0
+}
+
+// portProtocol return the protocol of the port mapping, tcp by default
+func portProtocol(mapping mesosproto.ContainerInfo_DockerInfo_PortMapping) string {
+	if mapping.GetProtocol() == "" {
+		return "tcp"
+	}
+	return strings.ToLower(mapping.GetProtocol())
+}
+
+// inRanges check if the value is inside one of the ranges
+func inRanges(ranges []mesosproto.Value_Range, value uint64) bool {
+	for _, r := range ranges {
+		if value >= r.Begin && value <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// freePort return the lowest port of the ranges which is not used
+func freePort(ranges []mesosproto.Value_Range, used map[uint64]bool) (uint64, bool) {
+	for _, r := range ranges {
+		if r.Begin > r.End {
+			continue
+		}
+		for port := r.Begin; ; port++ {
+			if port != 0 && !used[port] {
+				return port, true
+			}
+			if port == r.End {
+				break
+			}
+		}
+	}
+	return 0, false
+}
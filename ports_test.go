@@ -0,0 +1,119 @@
+package mesosutil
+
+import (
+	"reflect"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// portMapping create a port mapping of the host port to the container port
+func portMapping(hostPort uint32, containerPort uint32, protocol string) mesosproto.ContainerInfo_DockerInfo_PortMapping {
+	mapping := mesosproto.ContainerInfo_DockerInfo_PortMapping{HostPort: hostPort, ContainerPort: containerPort}
+	if protocol != "" {
+		mapping.Protocol = proto.String(protocol)
+	}
+	return mapping
+}
+
+func TestMatchPorts(t *testing.T) {
+	offered := []mesosproto.Resource{
+		rangesResource("ports", []mesosproto.Value_Range{{Begin: 31000, End: 31002}, {Begin: 31100, End: 31100}}),
+	}
+
+	tests := []struct {
+		name     string
+		mappings []mesosproto.ContainerInfo_DockerInfo_PortMapping
+		want     []uint32
+		ok       bool
+	}{
+		{"no mappings", nil, nil, true},
+		{"fixed port", []mesosproto.ContainerInfo_DockerInfo_PortMapping{portMapping(31100, 80, "")}, []uint32{31100}, true},
+		{"fixed port outside the ranges", []mesosproto.ContainerInfo_DockerInfo_PortMapping{portMapping(8080, 80, "")}, nil, false},
+		{"fixed port between the ranges", []mesosproto.ContainerInfo_DockerInfo_PortMapping{portMapping(31050, 80, "")}, nil, false},
+		{"one of the fixed ports outside the ranges", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(31000, 80, ""),
+			portMapping(8443, 443, ""),
+		}, nil, false},
+		{"several random ports", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(0, 80, ""),
+			portMapping(0, 443, ""),
+			portMapping(0, 8080, ""),
+			portMapping(0, 9090, ""),
+		}, []uint32{31000, 31001, 31002, 31100}, true},
+		{"more random ports than offered", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(0, 80, ""),
+			portMapping(0, 443, ""),
+			portMapping(0, 8080, ""),
+			portMapping(0, 9090, ""),
+			portMapping(0, 9091, ""),
+		}, nil, false},
+		{"random port does not take a fixed port", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(0, 80, ""),
+			portMapping(31000, 443, ""),
+		}, []uint32{31001, 31000}, true},
+		{"fixed port for tcp and udp", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(31001, 53, "tcp"),
+			portMapping(31001, 53, "udp"),
+		}, []uint32{31001, 31001}, true},
+		{"random port for tcp and udp", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(0, 53, "tcp"),
+			portMapping(0, 53, "udp"),
+			portMapping(0, 80, ""),
+		}, []uint32{31000, 31000, 31001}, true},
+		{"random port for the default protocol and udp", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(0, 53, ""),
+			portMapping(0, 53, "UDP"),
+		}, []uint32{31000, 31000}, true},
+		{"random ports of one container port and protocol", []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			portMapping(0, 80, "tcp"),
+			portMapping(0, 80, "tcp"),
+		}, []uint32{31000, 31001}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assigned, ok := MatchPorts(offered, tt.mappings)
+			if ok != tt.ok {
+				t.Fatalf("MatchPorts() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				if !reflect.DeepEqual(assigned, tt.mappings) {
+					t.Errorf("got mappings %v, want the unchanged mappings", assigned)
+				}
+				return
+			}
+
+			var ports []uint32
+			for n, mapping := range assigned {
+				ports = append(ports, mapping.HostPort)
+				if mapping.ContainerPort != tt.mappings[n].ContainerPort {
+					t.Errorf("mapping %d: got container port %d, want %d", n, mapping.ContainerPort, tt.mappings[n].ContainerPort)
+				}
+			}
+			if !reflect.DeepEqual(ports, tt.want) {
+				t.Errorf("got host ports %v, want %v", ports, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPortsDoesNotChangeMappings(t *testing.T) {
+	offered := []mesosproto.Resource{rangesResource("ports", []mesosproto.Value_Range{{Begin: 31000, End: 31000}})}
+	mappings := []mesosproto.ContainerInfo_DockerInfo_PortMapping{portMapping(0, 80, "")}
+
+	if _, ok := MatchPorts(offered, mappings); !ok {
+		t.Fatal("MatchPorts() = false, want true")
+	}
+	if mappings[0].HostPort != 0 {
+		t.Errorf("got host port %d in the mappings of the command, want 0", mappings[0].HostPort)
+	}
+}
+
+func TestMatchPortsWithoutPortsResource(t *testing.T) {
+	offered := []mesosproto.Resource{scalarResource("cpus", 1)}
+	if _, ok := MatchPorts(offered, []mesosproto.ContainerInfo_DockerInfo_PortMapping{portMapping(0, 80, "")}); ok {
+		t.Error("MatchPorts() = true, want false for an offer without ports")
+	}
+}
@@ -0,0 +1,198 @@
+package mesosutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReconcileImplicit ask mesos for the latest status of all tasks it know
+// about this framework
+func (c *Client) ReconcileImplicit() error {
+	return c.ReconcileImplicitContext(context.Background())
+}
+
+// ReconcileImplicitContext run an implicit reconciliation until the context is done
+func (c *Client) ReconcileImplicitContext(ctx context.Context) error {
+	logrus.WithField("func", "ReconcileImplicit").Debug("Implicit reconciliation")
+	return c.CallContext(ctx, &mesosproto.Call{
+		Type:      mesosproto.Call_RECONCILE,
+		Reconcile: &mesosproto.Call_Reconcile{},
+	})
+}
+
+// ReconcileExplicit ask mesos for the latest status of all tasks in State
+func (c *Client) ReconcileExplicit() error {
+	return c.ReconcileExplicitContext(context.Background())
+}
+
+// ReconcileExplicitContext run an explicit reconciliation until the context is done
+func (c *Client) ReconcileExplicitContext(ctx context.Context) error {
+	return c.reconcileTasks(ctx, reconcileTasks(c.config.State, nil))
+}
+
+func (c *Client) reconcileTasks(ctx context.Context, tasks []mesosproto.Call_Reconcile_Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	logrus.WithField("func", "ReconcileExplicit").Debug("Explicit reconciliation of ", len(tasks), " tasks")
+	return c.CallContext(ctx, &mesosproto.Call{
+		Type: mesosproto.Call_RECONCILE,
+		Reconcile: &mesosproto.Call_Reconcile{
+			Tasks: tasks,
+		},
+	})
+}
+
+// reconcileTasks build the reconcile tasks of the state. If filter is not
+// nil, only the task ids of the filter are used.
+func reconcileTasks(state map[string]State, filter map[string]bool) []mesosproto.Call_Reconcile_Task {
+	var tasks []mesosproto.Call_Reconcile_Task
+	for key, task := range state {
+		taskID := stateTaskID(key, task)
+		if filter != nil && !filter[taskID] {
+			continue
+		}
+		reconcile := mesosproto.Call_Reconcile_Task{
+			TaskID: mesosproto.TaskID{Value: taskID},
+		}
+		if task.Status != nil && task.Status.AgentID != nil {
+			reconcile.AgentID = task.Status.AgentID
+		}
+		tasks = append(tasks, reconcile)
+	}
+	return tasks
+}
+
+// stateTaskID return the mesos task id of an entry of the State
+func stateTaskID(key string, task State) string {
+	if task.Status != nil && task.Status.TaskID.Value != "" {
+		return task.Status.TaskID.Value
+	}
+	if task.Command.TaskID != "" {
+		return task.Command.TaskID
+	}
+	return key
+}
+
+// ReconcileImplicit run an implicit reconciliation with the default client
+func ReconcileImplicit() error {
+	return defaultClient.ReconcileImplicit()
+}
+
+// ReconcileExplicit run an explicit reconciliation with the default client
+func ReconcileExplicit() error {
+	return defaultClient.ReconcileExplicit()
+}
+
+// Reconciler run the reconciliation periodically. The explicit
+// reconciliation is repeated with backoff until every task in State got a
+// fresh status, then an implicit reconciliation follow.
+type Reconciler struct {
+	client *Client
+	// Interval between two reconciliation runs
+	Interval time.Duration
+	// Backoff between the explicit reconciliations of one run
+	Backoff Backoff
+	// MaxAttempts of the explicit reconciliation of one run, 0 is unlimited
+	MaxAttempts int
+
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+// NewReconciler create a reconciler of the client
+func (c *Client) NewReconciler() *Reconciler {
+	return &Reconciler{
+		client:   c,
+		Interval: 15 * time.Minute,
+		Backoff: Backoff{
+			Initial: 5 * time.Second,
+			Max:     2 * time.Minute,
+			Factor:  2,
+			Jitter:  0.2,
+		},
+		MaxAttempts: 10,
+	}
+}
+
+// Middleware mark the tasks of UPDATE events as reconciled. It has to be
+// registered at the dispatcher of the client.
+func (r *Reconciler) Middleware(next EventHandler) EventHandler {
+	return func(event *mesosproto.Event) error {
+		if event.Type == mesosproto.Event_UPDATE && event.Update != nil {
+			r.mu.Lock()
+			delete(r.pending, event.Update.Status.TaskID.Value)
+			r.mu.Unlock()
+		}
+		return next(event)
+	}
+}
+
+// Pending return the ids of the tasks which did not get a fresh status yet
+func (r *Reconciler) Pending() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []string
+	for taskID := range r.pending {
+		pending = append(pending, taskID)
+	}
+	return pending
+}
+
+// Run the reconciliation every Interval until the context is done
+func (r *Reconciler) Run(ctx context.Context) error {
+	for {
+		err := r.Reconcile(ctx)
+		if err != nil && ctx.Err() == nil {
+			logrus.WithField("func", "Reconciler.Run").Error("Reconciliation: ", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.Interval):
+		}
+	}
+}
+
+// Reconcile run one explicit reconciliation of all tasks in State until
+// every task got a fresh status, followed by an implicit reconciliation
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	r.mu.Lock()
+	r.pending = make(map[string]bool)
+	for key, task := range r.client.config.State {
+		r.pending[stateTaskID(key, task)] = true
+	}
+	r.mu.Unlock()
+
+	for attempt := 1; r.MaxAttempts == 0 || attempt <= r.MaxAttempts; attempt++ {
+		r.mu.Lock()
+		tasks := reconcileTasks(r.client.config.State, r.pending)
+		r.mu.Unlock()
+		if len(tasks) == 0 {
+			break
+		}
+
+		err := r.client.reconcileTasks(ctx, tasks)
+		if err != nil {
+			logrus.WithField("func", "Reconcile").Error("Explicit reconciliation: ", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.Backoff.Duration(attempt)):
+		}
+	}
+
+	if pending := r.Pending(); len(pending) > 0 {
+		logrus.WithField("func", "Reconcile").Warn("No fresh status of tasks: ", pending)
+	}
+
+	return r.client.ReconcileImplicitContext(ctx)
+}
@@ -0,0 +1,50 @@
+package mesosutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxRecordSize is the biggest RecordIO record we will read from the mesos master
+var MaxRecordSize uint64 = 64 << 20
+
+// recordIOReader read RecordIO framed records. Every record is prefixed
+// with its length in bytes as decimal number followed by a newline.
+type recordIOReader struct {
+	r *bufio.Reader
+}
+
+func newRecordIOReader(r io.Reader) *recordIOReader {
+	return &recordIOReader{r: bufio.NewReader(r)}
+}
+
+// ReadRecord read the next complete record out of the stream
+func (rr *recordIOReader) ReadRecord() ([]byte, error) {
+	header, err := rr.r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && header != "" {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	size, err := strconv.ParseUint(strings.TrimSpace(header), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recordio header %q: %w", header, err)
+	}
+	if size > MaxRecordSize {
+		return nil, fmt.Errorf("recordio record of %d bytes exceeds the limit of %d bytes", size, MaxRecordSize)
+	}
+
+	record := make([]byte, size)
+	if _, err := io.ReadFull(rr.r, record); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,90 @@
+package mesosutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadRecord(t *testing.T) {
+	large := strings.Repeat("x", 1<<20)
+
+	tests := []struct {
+		name    string
+		stream  string
+		records []string
+		err     error
+	}{
+		{"empty stream", "", nil, io.EOF},
+		{"one record", "5\nhello", []string{"hello"}, io.EOF},
+		{"several records", "3\nabc5\nhello0\n2\n{}", []string{"abc", "hello", "", "{}"}, io.EOF},
+		{"header with spaces", " 3 \nabc", []string{"abc"}, io.EOF},
+		{"large record", strconv.Itoa(len(large)) + "\n" + large, []string{large}, io.EOF},
+		{"truncated header", "3\nabc12", []string{"abc"}, io.ErrUnexpectedEOF},
+		{"truncated body", "3\nabc5\nhel", []string{"abc"}, io.ErrUnexpectedEOF},
+		{"header without body", "5\n", nil, io.ErrUnexpectedEOF},
+	}
+
+	readers := map[string]func(io.Reader) io.Reader{
+		"whole":    func(r io.Reader) io.Reader { return r },
+		"one byte": iotest.OneByteReader,
+		"data err": iotest.DataErrReader,
+		"half":     iotest.HalfReader,
+	}
+
+	for _, tt := range tests {
+		for readerName, wrap := range readers {
+			t.Run(tt.name+"/"+readerName, func(t *testing.T) {
+				reader := newRecordIOReader(wrap(strings.NewReader(tt.stream)))
+				for _, want := range tt.records {
+					record, err := reader.ReadRecord()
+					if err != nil {
+						t.Fatalf("ReadRecord() = %v, want a record of %d bytes", err, len(want))
+					}
+					if string(record) != want {
+						t.Fatalf("got record of %d bytes, want %d bytes", len(record), len(want))
+					}
+				}
+				if _, err := reader.ReadRecord(); !errors.Is(err, tt.err) {
+					t.Errorf("ReadRecord() = %v at the end of the stream, want %v", err, tt.err)
+				}
+			})
+		}
+	}
+}
+
+func TestReadRecordInvalidHeader(t *testing.T) {
+	reader := newRecordIOReader(strings.NewReader("abc\nhello"))
+	if _, err := reader.ReadRecord(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("ReadRecord() = %v, want an invalid header error", err)
+	}
+}
+
+func TestReadRecordMaxRecordSize(t *testing.T) {
+	previous := MaxRecordSize
+	defer func() { MaxRecordSize = previous }()
+	MaxRecordSize = 4
+
+	// the record is rejected before its body is read
+	body := &bytes.Buffer{}
+	body.WriteString("4\nabcd5\nhello")
+	reader := newRecordIOReader(iotest.OneByteReader(body))
+
+	if record, err := reader.ReadRecord(); err != nil || string(record) != "abcd" {
+		t.Fatalf("ReadRecord() = %q, %v, want the record of the limit", record, err)
+	}
+	record, err := reader.ReadRecord()
+	if err == nil || record != nil {
+		t.Fatalf("ReadRecord() = %q, %v, want an error for the record over the limit", record, err)
+	}
+	if !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("got error %v, want the limit error", err)
+	}
+	if body.Len() != len("hello") {
+		t.Errorf("%d bytes of the stream are left, want the unread body of %d bytes", body.Len(), len("hello"))
+	}
+}
@@ -0,0 +1,275 @@
+package mesosutil
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// ReservationLabel is the key of the reservation label which hold the
+// reservation id of a command
+const ReservationLabel = "mesos-util.reservation"
+
+// ErrNoRole is returned if resources should be reserved without a
+// FrameworkRole
+var ErrNoRole = errors.New("mesos reservations need a framework role")
+
+// Reservation is a dynamic reservation of the resources of a command on
+// one agent
+type Reservation struct {
+	ID        string
+	AgentID   mesosproto.AgentID
+	Resources []mesosproto.Resource
+}
+
+// ReservationID return the id the resources of the command are reserved
+// with. It is the ReservationID or the TaskName of the command, but never
+// the TaskID, which change with every launch of the task. Instances with
+// the same id share the reservation on one agent.
+func ReservationID(cmd Command) string {
+	if cmd.ReservationID != "" {
+		return cmd.ReservationID
+	}
+	return cmd.TaskName
+}
+
+// reservationLabel return the reservation id of the resource, or an empty
+// string if the resource was not reserved for a command
+func reservationLabel(res mesosproto.Resource) string {
+	var labels *mesosproto.Labels
+	if n := len(res.Reservations); n > 0 {
+		labels = res.Reservations[n-1].Labels
+	} else if res.Reservation != nil {
+		labels = res.Reservation.Labels
+	}
+	if labels == nil {
+		return ""
+	}
+	for _, label := range labels.Labels {
+		if label.Key == ReservationLabel {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+// ReservedResources return the resources which are reserved with the id
+func ReservedResources(resources []mesosproto.Resource, id string) []mesosproto.Resource {
+	var reserved []mesosproto.Resource
+	for _, res := range resources {
+		if id != "" && reservationLabel(res) == id {
+			reserved = append(reserved, res)
+		}
+	}
+	return reserved
+}
+
+// ReserveOperation create the operation to reserve the resources. The
+// resources have to carry their new reservation.
+func ReserveOperation(resources ...mesosproto.Resource) mesosproto.Offer_Operation {
+	return mesosproto.Offer_Operation{
+		Type: mesosproto.Offer_Operation_RESERVE,
+		Reserve: &mesosproto.Offer_Operation_Reserve{
+			Resources: resources,
+		},
+	}
+}
+
+// UnreserveOperation create the operation to release the reserved resources
+func UnreserveOperation(resources ...mesosproto.Resource) mesosproto.Offer_Operation {
+	return mesosproto.Offer_Operation{
+		Type: mesosproto.Offer_Operation_UNRESERVE,
+		Unreserve: &mesosproto.Offer_Operation_Unreserve{
+			Resources: resources,
+		},
+	}
+}
+
+// ReservationResources take the unreserved resources of the command out of
+// the offer and add a dynamic reservation for the FrameworkRole with the
+// reservation id as label. The disk of the persistent volumes of the
+// command is reserved too. It return the command with the assigned host
+// ports and the resources to reserve.
+func (c *Client) ReservationResources(offer mesosproto.Offer, cmd Command) (Command, []mesosproto.Resource, error) {
+	role := c.config.FrameworkRole
+	if role == "" {
+		return cmd, nil, ErrNoRole
+	}
+
+	var unreserved []mesosproto.Resource
+	for _, res := range offer.Resources {
+		if ReservationRole(res) == "" {
+			unreserved = append(unreserved, res)
+		}
+	}
+	need := cmd
+	for _, volume := range cmd.PersistentVolumes {
+		need.Disk += volume.Size
+	}
+	need, resources, ok := allocateResources(unreserved, need, role)
+	if !ok {
+		return cmd, nil, ErrNoResources
+	}
+	cmd.DockerPortMappings = need.DockerPortMappings
+
+	reservation := mesosproto.Resource_ReservationInfo{
+		Type: mesosproto.Resource_ReservationInfo_DYNAMIC.Enum(),
+		Role: proto.String(role),
+		Labels: &mesosproto.Labels{Labels: []mesosproto.Label{
+			{Key: ReservationLabel, Value: proto.String(ReservationID(cmd))},
+		}},
+	}
+	if principal := c.config.FrameworkInfo.GetPrincipal(); principal != "" {
+		reservation.Principal = proto.String(principal)
+	}
+	for n := range resources {
+		resources[n].Role = nil
+		resources[n].Reservation = nil
+		resources[n].Reservations = append(append([]mesosproto.Resource_ReservationInfo{}, resources[n].Reservations...), reservation)
+	}
+	return cmd, resources, nil
+}
+
+// Reserve the resources of the command on the agent of the offer for the
+// FrameworkRole. The reservation is tracked by the ReservationID of the
+// command and will come back with the next offers of the agent.
+func (c *Client) Reserve(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (Reservation, error) {
+	return c.ReserveContext(context.Background(), offer, cmd, filters)
+}
+
+// ReserveContext reserve the resources of the command until the context is done
+func (c *Client) ReserveContext(ctx context.Context, offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (Reservation, error) {
+	cmd, resources, err := c.ReservationResources(offer, cmd)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	logrus.WithField("func", "Reserve").Debug("Reserve resources of ", ReservationID(cmd), " on ", offer.GetHostname())
+	err = c.AcceptAgentContext(ctx, offer.AgentID, []mesosproto.OfferID{offer.ID}, []mesosproto.Offer_Operation{ReserveOperation(resources...)}, filters)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	reservation := Reservation{ID: ReservationID(cmd), AgentID: offer.AgentID, Resources: resources}
+	c.trackReservation(reservation)
+	return reservation, nil
+}
+
+// LaunchReserved launch the command only on the resources which are
+// reserved for it on the agent of the offer
+func (c *Client) LaunchReserved(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	return c.LaunchReservedContext(context.Background(), offer, cmd, filters)
+}
+
+// LaunchReservedContext launch the command on its reservation until the context is done
+func (c *Client) LaunchReservedContext(ctx context.Context, offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	reserved := ReservedResources(offer.Resources, ReservationID(cmd))
+	cmd, resources, ok := allocateResources(reserved, cmd, c.config.FrameworkRole)
+	if !ok {
+		return mesosproto.TaskInfo{}, ErrNoResources
+	}
+
+	task := c.prepareTaskInfo(cmd, offer, resources)
+	logrus.WithField("func", "LaunchReserved").Debug("Launch task ", task.TaskID.Value, " on reservation ", ReservationID(cmd))
+
+	err := c.AcceptAgentContext(ctx, offer.AgentID, []mesosproto.OfferID{offer.ID}, []mesosproto.Offer_Operation{LaunchOperation(task)}, filters)
+	return task, err
+}
+
+// Unreserve release all resources of the offer which are reserved with the id
+func (c *Client) Unreserve(offer mesosproto.Offer, id string, filters *mesosproto.Filters) error {
+	return c.UnreserveContext(context.Background(), offer, id, filters)
+}
+
+// UnreserveContext release the reservation until the context is done.
+// Persistent volumes on the reservation have to be destroyed before.
+func (c *Client) UnreserveContext(ctx context.Context, offer mesosproto.Offer, id string, filters *mesosproto.Filters) error {
+	var reserved []mesosproto.Resource
+	for _, res := range ReservedResources(offer.Resources, id) {
+		if persistenceID(res) == "" {
+			reserved = append(reserved, res)
+		}
+	}
+	if len(reserved) == 0 {
+		return ErrNoResources
+	}
+
+	logrus.WithField("func", "Unreserve").Debug("Unreserve resources of ", id, " on ", offer.GetHostname())
+	err := c.AcceptAgentContext(ctx, offer.AgentID, []mesosproto.OfferID{offer.ID}, []mesosproto.Offer_Operation{UnreserveOperation(reserved...)}, filters)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	delete(c.reservations, id)
+	c.mu.Unlock()
+	return nil
+}
+
+// TrackReservations remember the reservations of the framework found in
+// the offers, e.g. to get them back after a restart of the framework
+func (c *Client) TrackReservations(offers *mesosproto.Event_Offers) {
+	for _, offer := range offers.GetOffers() {
+		found := make(map[string][]mesosproto.Resource)
+		for _, res := range offer.Resources {
+			if id := reservationLabel(res); id != "" && ReservationRole(res) == c.config.FrameworkRole {
+				found[id] = append(found[id], res)
+			}
+		}
+		for id, resources := range found {
+			c.trackReservation(Reservation{ID: id, AgentID: offer.AgentID, Resources: resources})
+		}
+	}
+}
+
+// trackReservation remember the reservation by its id
+func (c *Client) trackReservation(reservation Reservation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reservations == nil {
+		c.reservations = make(map[string]Reservation)
+	}
+	c.reservations[reservation.ID] = reservation
+}
+
+// Reservation return the tracked reservation with the id
+func (c *Client) Reservation(id string) (Reservation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reservation, ok := c.reservations[id]
+	return reservation, ok
+}
+
+// Reservations return all tracked reservations, sorted by id
+func (c *Client) Reservations() []Reservation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reservations := make([]Reservation, 0, len(c.reservations))
+	for _, reservation := range c.reservations {
+		reservations = append(reservations, reservation)
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].ID < reservations[j].ID
+	})
+	return reservations
+}
+
+// Reserve the resources of the command with the default client
+func Reserve(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (Reservation, error) {
+	return defaultClient.Reserve(offer, cmd, filters)
+}
+
+// LaunchReserved launch the command on its reservation with the default client
+func LaunchReserved(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	return defaultClient.LaunchReserved(offer, cmd, filters)
+}
+
+// Unreserve release the reservation with the default client
+func Unreserve(offer mesosproto.Offer, id string, filters *mesosproto.Filters) error {
+	return defaultClient.Unreserve(offer, id, filters)
+}
@@ -0,0 +1,78 @@
+package mesosutil
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestReservationID(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Command
+		want string
+	}{
+		{"task name", Command{TaskName: "db", TaskID: "db.1"}, "db"},
+		{"reservation id", Command{TaskName: "db", TaskID: "db.1", ReservationID: "db-0"}, "db-0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReservationID(tt.cmd); got != tt.want {
+				t.Errorf("ReservationID() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReservationIDSurviveDecodeTask(t *testing.T) {
+	encoded, err := json.Marshal(Command{TaskName: "db", ReservationID: "db-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ReservationID(DecodeTask(string(encoded))); got != "db-0" {
+		t.Errorf("got reservation id %s after DecodeTask, want db-0", got)
+	}
+}
+
+func TestLaunchReservedAfterRestart(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+
+	offer := testOffer("1", "agent1", 4, 4096)
+	cmd, resources, err := c.ReservationResources(offer, Command{TaskName: "db", TaskID: "db.1", CPU: 1, Memory: 512})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the next offer of the agent carry the reservation
+	next := testOffer("2", "agent1", 3, 3584)
+	next.Resources = append(next.Resources, resources...)
+
+	// the restarted task get a new task id and still find its reservation
+	cmd.TaskID = "db.2"
+	task, err := c.LaunchReserved(next, cmd, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range task.Resources {
+		if reservationLabel(res) != "db" {
+			t.Errorf("task use resource %s without the reservation of db", res.GetName())
+		}
+	}
+
+	// other commands do not use the reservation
+	other, _, ok := allocateResources(next.Resources, Command{TaskName: "web", CPU: 4, Memory: 128}, "web")
+	if ok {
+		t.Errorf("command %s got the reserved resources of db", other.TaskName)
+	}
+	if _, err := c.LaunchReserved(next, Command{TaskName: "web", CPU: 1, Memory: 128}, nil); !errors.Is(err, ErrNoResources) {
+		t.Errorf("LaunchReserved() of an other command = %v, want ErrNoResources", err)
+	}
+}
+
+func TestReserveWithoutRole(t *testing.T) {
+	c := NewClient(&FrameworkConfig{})
+	if _, _, err := c.ReservationResources(testOffer("1", "agent1", 4, 4096), Command{TaskName: "db", CPU: 1}); !errors.Is(err, ErrNoRole) {
+		t.Errorf("ReservationResources() = %v, want ErrNoRole", err)
+	}
+}
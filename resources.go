@@ -0,0 +1,294 @@
+package mesosutil
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNoResources is returned if the offer has not enough resources for
+// the command
+var ErrNoResources = errors.New("offer has not enough resources")
+
+// scalarValue return the sum of all scalar resources with the name
+func scalarValue(resources []mesosproto.Resource, name string) float64 {
+	value := 0.0
+	for _, res := range resources {
+		if res.GetName() == name && res.Scalar != nil {
+			value += res.Scalar.GetValue()
+		}
+	}
+	return value
+}
+
+// ReservationRole return the role the resource is reserved for, or an
+// empty string if the resource is not reserved
+func ReservationRole(res mesosproto.Resource) string {
+	if n := len(res.Reservations); n > 0 {
+		return res.Reservations[n-1].GetRole()
+	}
+	if res.Reservation != nil && res.Reservation.GetRole() != "" {
+		return res.Reservation.GetRole()
+	}
+	if res.GetRole() != "*" {
+		return res.GetRole()
+	}
+	return ""
+}
+
+// usableResource check if a task of the role can use the resource. The
+// resource has to be allocated to the role, and it has to be unreserved or
+// reserved for the role or one of its parent roles. Persistent volumes are
+// never used for plain resource requests. An empty role can use everything.
+func usableResource(res mesosproto.Resource, role string) bool {
+	if res.Disk != nil && res.Disk.Persistence != nil {
+		return false
+	}
+	if role == "" {
+		return true
+	}
+	if res.AllocationInfo != nil && res.AllocationInfo.GetRole() != "" && res.AllocationInfo.GetRole() != role {
+		return false
+	}
+	reserved := ReservationRole(res)
+	return reserved == "" || reserved == role || strings.HasPrefix(role, reserved+"/")
+}
+
+// MatchResources take the resources the command need out of the offered
+// resources for a task of the role. Resources reserved for the role are
+// preferred over unreserved ones. The returned resources keep the
+// reservations and allocation_info of the offer, so they can be used in
+// the TaskInfo directly.
+func MatchResources(ressource []mesosproto.Resource, cmd Command, role string) ([]mesosproto.Resource, bool) {
+	_, allocated, ok := allocateResources(ressource, cmd, role)
+	return allocated, ok
+}
+
+// allocateResources take the resources of the command out of the offer. It
+// return the command with the assigned host ports and the allocated resources.
+// Resources reserved for an other command are not used.
+func allocateResources(ressource []mesosproto.Resource, cmd Command, role string) (Command, []mesosproto.Resource, bool) {
+	var usable []mesosproto.Resource
+	for _, res := range ressource {
+		if id := reservationLabel(res); id != "" && id != ReservationID(cmd) {
+			continue
+		}
+		if usableResource(res, role) {
+			usable = append(usable, res)
+		}
+	}
+	// prefer the reserved resources
+	sort.SliceStable(usable, func(i, j int) bool {
+		return ReservationRole(usable[i]) != "" && ReservationRole(usable[j]) == ""
+	})
+
+	var allocated []mesosproto.Resource
+	for _, need := range scalarNeeds(cmd) {
+		taken, ok := takeScalar(usable, need.name, need.value)
+		if !ok {
+			logrus.Debug("Offer has not enough ", need.name)
+			return cmd, nil, false
+		}
+		allocated = append(allocated, taken...)
+	}
+
+	mappings, ok := MatchPorts(usable, cmd.DockerPortMappings)
+	if !ok {
+		return cmd, nil, false
+	}
+	cmd.DockerPortMappings = mappings
+
+	seen := make(map[uint32]bool)
+	for _, port := range mappings {
+		if seen[port.HostPort] {
+			continue
+		}
+		seen[port.HostPort] = true
+		allocated = append(allocated, takePort(usable, uint64(port.HostPort)))
+	}
+
+	return cmd, allocated, true
+}
+
+type scalarNeed struct {
+	name  string
+	value float64
+}
+
+// scalarNeeds return the scalar resources of the command
+func scalarNeeds(cmd Command) []scalarNeed {
+	needs := []scalarNeed{
+		{"cpus", cmd.CPU},
+		{"mem", cmd.Memory},
+		{"disk", cmd.Disk},
+		{"gpus", cmd.Gpus},
+	}
+
+	names := make([]string, 0, len(cmd.ScalarResources))
+	for name := range cmd.ScalarResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		needs = append(needs, scalarNeed{name, cmd.ScalarResources[name]})
+	}
+
+	var positive []scalarNeed
+	for _, need := range needs {
+		if need.value > 0 {
+			positive = append(positive, need)
+		}
+	}
+	return positive
+}
+
+// takeScalar take the value out of the scalar resources with the name. The
+// value can be split over several resources, e.g. reserved and unreserved.
+func takeScalar(usable []mesosproto.Resource, name string, value float64) ([]mesosproto.Resource, bool) {
+	var taken []mesosproto.Resource
+	for _, res := range usable {
+		if value <= 0 {
+			break
+		}
+		if res.GetName() != name || res.Scalar == nil || res.Scalar.GetValue() <= 0 {
+			continue
+		}
+		amount := res.Scalar.GetValue()
+		if amount > value {
+			amount = value
+		}
+		value -= amount
+
+		part := resourceMeta(res)
+		part.Scalar = &mesosproto.Value_Scalar{Value: amount}
+		taken = append(taken, part)
+	}
+	return taken, value <= 1e-9
+}
+
+// takePort return the ports resource of the offer which contain the port
+func takePort(usable []mesosproto.Resource, port uint64) mesosproto.Resource {
+	for _, res := range usable {
+		if res.GetName() == "ports" && res.Ranges != nil && inRanges(res.Ranges.Range, port) {
+			part := resourceMeta(res)
+			part.Ranges = &mesosproto.Value_Ranges{Range: []mesosproto.Value_Range{{Begin: port, End: port}}}
+			return part
+		}
+	}
+	return rangesResource("ports", []mesosproto.Value_Range{{Begin: port, End: port}})
+}
+
+// resourceMeta copy the resource without its value
+func resourceMeta(res mesosproto.Resource) mesosproto.Resource {
+	res.Scalar = nil
+	res.Ranges = nil
+	res.Set = nil
+	return res
+}
+
+// sameResource check if both resources are the same kind of resource,
+// with the same role, reservations and allocation
+func sameResource(a mesosproto.Resource, b mesosproto.Resource) bool {
+	if a.GetName() != b.GetName() {
+		return false
+	}
+	metaA := resourceMeta(a)
+	metaB := resourceMeta(b)
+	return proto.Equal(&metaA, &metaB)
+}
+
+// copyResources copy the resources, so the values can be changed without
+// changing the offer
+func copyResources(resources []mesosproto.Resource) []mesosproto.Resource {
+	cp := make([]mesosproto.Resource, len(resources))
+	for n, res := range resources {
+		cp[n] = res
+		if res.Scalar != nil {
+			scalar := *res.Scalar
+			cp[n].Scalar = &scalar
+		}
+		if res.Ranges != nil {
+			ranges := make([]mesosproto.Value_Range, len(res.Ranges.Range))
+			copy(ranges, res.Ranges.Range)
+			cp[n].Ranges = &mesosproto.Value_Ranges{Range: ranges}
+		}
+	}
+	return cp
+}
+
+// subtractResources remove the used resources from the remaining resources
+// of an offer. The remaining resources are changed in place.
+func subtractResources(remaining []mesosproto.Resource, used []mesosproto.Resource) {
+	for _, use := range used {
+		switch {
+		case use.Scalar != nil:
+			subtractScalar(remaining, use, use.Scalar.GetValue())
+		case use.Ranges != nil:
+			for _, r := range use.Ranges.Range {
+				for value := r.Begin; value <= r.End; value++ {
+					subtractRangeValue(remaining, use, value)
+				}
+			}
+		}
+	}
+}
+
+// subtractScalar remove the value from the first matching resource with
+// enough of it
+func subtractScalar(remaining []mesosproto.Resource, use mesosproto.Resource, value float64) {
+	for n := range remaining {
+		res := &remaining[n]
+		if res.Scalar == nil || !sameResource(*res, use) {
+			continue
+		}
+		if res.Scalar.Value >= value {
+			res.Scalar.Value -= value
+			return
+		}
+	}
+}
+
+// subtractRangeValue remove one value out of the matching ranges resources
+func subtractRangeValue(remaining []mesosproto.Resource, use mesosproto.Resource, value uint64) {
+	for n := range remaining {
+		res := &remaining[n]
+		if res.Ranges == nil || !sameResource(*res, use) {
+			continue
+		}
+		for i, r := range res.Ranges.Range {
+			if value < r.Begin || value > r.End {
+				continue
+			}
+			var split []mesosproto.Value_Range
+			if value > r.Begin {
+				split = append(split, mesosproto.Value_Range{Begin: r.Begin, End: value - 1})
+			}
+			if value < r.End {
+				split = append(split, mesosproto.Value_Range{Begin: value + 1, End: r.End})
+			}
+			ranges := append([]mesosproto.Value_Range{}, res.Ranges.Range[:i]...)
+			ranges = append(ranges, split...)
+			ranges = append(ranges, res.Ranges.Range[i+1:]...)
+			res.Ranges.Range = ranges
+			return
+		}
+	}
+}
+
+// EnableGPUResources add the GPU_RESOURCES capability to the framework, so
+// mesos offer the gpus of the agents to the framework
+func EnableGPUResources(cfg *FrameworkConfig) {
+	for _, capability := range cfg.FrameworkInfo.Capabilities {
+		if capability.Type == mesosproto.FrameworkInfo_Capability_GPU_RESOURCES {
+			return
+		}
+	}
+	cfg.FrameworkInfo.Capabilities = append(cfg.FrameworkInfo.Capabilities, mesosproto.FrameworkInfo_Capability{
+		Type: mesosproto.FrameworkInfo_Capability_GPU_RESOURCES,
+	})
+}
@@ -0,0 +1,85 @@
+package mesosutil
+
+import (
+	"context"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RetryPolicy describe which calls are send again after a retryable error
+type RetryPolicy struct {
+	// MaxAttempts is how often a call will be send at most
+	MaxAttempts int
+	Backoff     Backoff
+	// CallTypes which are idempotent and can be retried
+	CallTypes map[mesosproto.Call_Type]bool
+	// OnRetry is called before a call will be send again
+	OnRetry func(callType mesosproto.Call_Type, attempt int, err error)
+}
+
+// DefaultRetryPolicy retry the idempotent calls
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	Backoff: Backoff{
+		Initial: 200 * time.Millisecond,
+		Max:     5 * time.Second,
+		Factor:  2,
+		Jitter:  0.2,
+	},
+	CallTypes: map[mesosproto.Call_Type]bool{
+		mesosproto.Call_KILL:                         true,
+		mesosproto.Call_RECONCILE:                    true,
+		mesosproto.Call_RECONCILE_OPERATIONS:         true,
+		mesosproto.Call_ACKNOWLEDGE:                  true,
+		mesosproto.Call_ACKNOWLEDGE_OPERATION_STATUS: true,
+		mesosproto.Call_REVIVE:                       true,
+		mesosproto.Call_SUPPRESS:                     true,
+	},
+}
+
+// Copy return a copy of the policy with its own CallTypes, so changing the
+// copy does not change the original policy
+func (p RetryPolicy) Copy() RetryPolicy {
+	callTypes := make(map[mesosproto.Call_Type]bool, len(p.CallTypes))
+	for callType, retry := range p.CallTypes {
+		callTypes[callType] = retry
+	}
+	p.CallTypes = callTypes
+	return p
+}
+
+// shouldRetry return true if the call can be send again after the failed
+// attempt. The error of the attempt has to be retryable.
+func (p RetryPolicy) shouldRetry(callType mesosproto.Call_Type, attempt int) bool {
+	return p.CallTypes[callType] && attempt < p.MaxAttempts
+}
+
+// Retries return how many calls were send again by the client
+func (c *Client) Retries() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.retries
+}
+
+// retry wait before the call will be send again. It return false if the
+// context is done.
+func (c *Client) retry(ctx context.Context, callType mesosproto.Call_Type, attempt int, err error) bool {
+	c.mu.Lock()
+	c.retries++
+	c.mu.Unlock()
+
+	logrus.WithField("func", "retry").Warn("Retry ", callType.String(), " call (attempt ", attempt+1, "): ", err.Error())
+	if c.Retry.OnRetry != nil {
+		c.Retry.OnRetry(callType, attempt, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(c.Retry.Backoff.Duration(attempt)):
+		return true
+	}
+}
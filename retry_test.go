@@ -0,0 +1,110 @@
+package mesosutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+func TestRetryPolicyPerClient(t *testing.T) {
+	a := NewClient(&FrameworkConfig{})
+	b := NewClient(&FrameworkConfig{})
+
+	a.Retry.CallTypes[mesosproto.Call_ACCEPT] = true
+	delete(a.Retry.CallTypes, mesosproto.Call_KILL)
+
+	if b.Retry.CallTypes[mesosproto.Call_ACCEPT] || !b.Retry.CallTypes[mesosproto.Call_KILL] {
+		t.Error("changing the retry policy of one client changed an other client")
+	}
+	if DefaultRetryPolicy.CallTypes[mesosproto.Call_ACCEPT] || !DefaultRetryPolicy.CallTypes[mesosproto.Call_KILL] {
+		t.Error("changing the retry policy of a client changed the DefaultRetryPolicy")
+	}
+}
+
+func TestRetryIdempotentCalls(t *testing.T) {
+	var requests int32
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer master.Close()
+
+	c := NewClient(&FrameworkConfig{MesosMasterServer: strings.TrimPrefix(master.URL, "http://")})
+	c.Retry.Backoff = Backoff{Initial: time.Millisecond, Max: time.Millisecond, Factor: 1}
+
+	if err := c.Kill("task-1", "agent-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Retries(); got != 2 {
+		t.Errorf("Retries() = %d, want 2", got)
+	}
+
+	// ACCEPT is not idempotent and must not be retried
+	atomic.StoreInt32(&requests, 0)
+	if err := c.Accept(nil, nil, nil); !IsNotLeader(err) {
+		t.Errorf("Accept() = %v, want the 503 of the master", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("ACCEPT was send %d times, want 1", got)
+	}
+}
+
+func TestRetryAfterTimeoutOfAttempt(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first request stall until the test is done
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-release
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer master.Close()
+	defer close(release)
+
+	c := NewClient(&FrameworkConfig{MesosMasterServer: strings.TrimPrefix(master.URL, "http://")})
+	c.Timeout = 100 * time.Millisecond
+	c.Retry.Backoff = Backoff{Initial: time.Millisecond, Max: time.Millisecond, Factor: 1}
+
+	if err := c.Kill("task-1", "agent-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Retries(); got != 1 {
+		t.Errorf("Retries() = %d, want 1", got)
+	}
+}
+
+func TestNoRetryAfterDeadlineOfCaller(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+	}))
+	defer master.Close()
+	defer close(release)
+
+	c := NewClient(&FrameworkConfig{MesosMasterServer: strings.TrimPrefix(master.URL, "http://")})
+	c.Timeout = time.Second
+	c.Retry.Backoff = Backoff{Initial: time.Millisecond, Max: time.Millisecond, Factor: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := c.KillContext(ctx, "task-1", "agent-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("KillContext() = %v, want context.DeadlineExceeded", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("KILL was send %d times, want 1", got)
+	}
+}
@@ -0,0 +1,160 @@
+package mesosutil
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// Subscription is an open SUBSCRIBE connection to the mesos master
+type Subscription struct {
+	ctx    context.Context
+	events chan mesosproto.Event
+	body   io.ReadCloser
+	done   chan struct{}
+	once   sync.Once
+	mu     sync.Mutex
+	err    error
+}
+
+// Subscribe open the SUBSCRIBE connection to the mesos master. The events
+// of the stream will be send into the Events channel of the subscription.
+func (c *Client) Subscribe() (*Subscription, error) {
+	return c.SubscribeContext(context.Background())
+}
+
+// SubscribeContext open the SUBSCRIBE connection to the mesos master. The
+// connection will be closed if the context is done.
+func (c *Client) SubscribeContext(ctx context.Context) (*Subscription, error) {
+	frameworkInfo := c.frameworkInfo()
+	subscribe := &mesosproto.Call{
+		Type: mesosproto.Call_SUBSCRIBE,
+		Subscribe: &mesosproto.Call_Subscribe{
+			FrameworkInfo: &frameworkInfo,
+		},
+	}
+	subscribe.FrameworkID = frameworkInfo.ID
+	body, err := c.Codec.Marshal(subscribe)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.url("/api/v1/scheduler"), bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", c.Codec.ContentType())
+	req.Header.Set("Accept", c.Codec.ContentType())
+	res, err := c.do(req)
+
+	if err != nil {
+		logrus.WithField("func", "Subscribe").Error("Could not connect to mesos master: ", err.Error())
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer closeBody(res)
+		return nil, newAPIError(res, mesosproto.Call_SUBSCRIBE)
+	}
+
+	streamID := res.Header.Get("Mesos-Stream-Id")
+	c.setStreamID(streamID)
+	logrus.WithField("func", "Subscribe").Debug("Mesos-Stream-Id: ", streamID)
+
+	sub := &Subscription{
+		ctx:    ctx,
+		events: make(chan mesosproto.Event),
+		body:   res.Body,
+		done:   make(chan struct{}),
+	}
+	go sub.read(c)
+
+	return sub, nil
+}
+
+// Subscribe open the SUBSCRIBE connection of the default client
+func Subscribe() (*Subscription, error) {
+	return defaultClient.Subscribe()
+}
+
+// SubscribeContext open the SUBSCRIBE connection of the default client
+func SubscribeContext(ctx context.Context) (*Subscription, error) {
+	return defaultClient.SubscribeContext(ctx)
+}
+
+// read decode the event stream until the connection get closed
+func (s *Subscription) read(c *Client) {
+	defer close(s.events)
+	defer s.body.Close()
+
+	reader := newRecordIOReader(s.body)
+	for {
+		record, err := reader.ReadRecord()
+		if err != nil {
+			s.setErr(err)
+			return
+		}
+
+		var event mesosproto.Event
+		err = c.Codec.Unmarshal(record, &event)
+		if err != nil {
+			logrus.WithField("func", "Subscription.read").Error("Could not decode event: ", err.Error())
+			s.setErr(err)
+			return
+		}
+
+		// remember the framework id mesos gave us
+		if event.Type == mesosproto.Event_SUBSCRIBED && event.Subscribed.GetFrameworkID() != nil {
+			c.setFrameworkID(event.Subscribed.FrameworkID)
+		}
+
+		select {
+		case s.events <- event:
+		case <-s.done:
+			return
+		case <-s.ctx.Done():
+			s.setErr(s.ctx.Err())
+			return
+		}
+	}
+}
+
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+		// the subscription was closed by us, that is not an error
+	default:
+		if s.ctx.Err() != nil {
+			s.err = s.ctx.Err()
+		} else if err != io.EOF {
+			s.err = err
+		}
+	}
+}
+
+// Events return the channel of the received events. The channel will be
+// closed if the connection to the mesos master get lost.
+func (s *Subscription) Events() <-chan mesosproto.Event {
+	return s.events
+}
+
+// Err return the error why the event stream stopped
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close the connection to the mesos master
+func (s *Subscription) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.body.Close()
+	})
+	return err
+}
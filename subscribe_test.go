@@ -0,0 +1,52 @@
+package mesosutil
+
+import (
+	"sync"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+func TestSubscribeWithConcurrentCalls(t *testing.T) {
+	master := newFakeMaster(t, mesosproto.Event{
+		Type: mesosproto.Event_SUBSCRIBED,
+		Subscribed: &mesosproto.Event_Subscribed{
+			FrameworkID: &mesosproto.FrameworkID{Value: "framework-1"},
+		},
+	})
+	c := NewClient(master.config())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			c.Revive()
+		}
+	}()
+
+	sub, err := c.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	event := <-sub.Events()
+	if event.Type != mesosproto.Event_SUBSCRIBED {
+		t.Fatalf("got event %s, want SUBSCRIBED", event.Type)
+	}
+	wg.Wait()
+
+	if got := c.StreamID(); got != "stream-1" {
+		t.Errorf("StreamID() = %q, want stream-1", got)
+	}
+	if got := c.FrameworkID().GetValue(); got != "framework-1" {
+		t.Errorf("FrameworkID() = %q, want framework-1", got)
+	}
+
+	c.Revive()
+	revives := master.received(mesosproto.Call_REVIVE)
+	if last := revives[len(revives)-1]; last.FrameworkID.GetValue() != "framework-1" {
+		t.Errorf("call has framework id %q, want framework-1", last.FrameworkID.GetValue())
+	}
+}
@@ -0,0 +1,310 @@
+package mesosutil
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// dockerNetworks map the NetworkMode of a command to the docker network
+var dockerNetworks = map[string]mesosproto.ContainerInfo_DockerInfo_Network{
+	"host":   mesosproto.ContainerInfo_DockerInfo_HOST,
+	"bridge": mesosproto.ContainerInfo_DockerInfo_BRIDGE,
+	"none":   mesosproto.ContainerInfo_DockerInfo_NONE,
+	"user":   mesosproto.ContainerInfo_DockerInfo_USER,
+}
+
+// PrepareTaskInfo build the TaskInfo of the command to launch it on the
+// agent of the offer. The docker containerizer is used if the ContainerType
+// of the command is "docker", otherwise the mesos containerizer. Host ports
+// 0 get a free port of the offer. The resources are taken out of the offer
+// for the FrameworkRole, so they carry its reservations and allocation_info.
+// It return ErrNoResources if the offer has not enough resources.
+func (c *Client) PrepareTaskInfo(cmd Command, offer mesosproto.Offer) (mesosproto.TaskInfo, error) {
+	cmd, resources, ok := allocateResources(offer.Resources, cmd, c.config.FrameworkRole)
+	if !ok {
+		return mesosproto.TaskInfo{}, ErrNoResources
+	}
+	return c.prepareTaskInfo(cmd, offer, resources), nil
+}
+
+// prepareTaskInfo build the TaskInfo of the command with the resources
+// allocated out of the offer
+func (c *Client) prepareTaskInfo(cmd Command, offer mesosproto.Offer, resources []mesosproto.Resource) mesosproto.TaskInfo {
+	taskID := cmd.TaskID
+	if taskID == "" {
+		taskID = cmd.TaskName + "." + strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+
+	task := mesosproto.TaskInfo{
+		Name:      cmd.TaskName,
+		TaskID:    mesosproto.TaskID{Value: taskID},
+		AgentID:   offer.AgentID,
+		Resources: resources,
+		Container: c.prepareContainer(cmd),
+	}
+
+	if cmd.Executor.ExecutorID != nil {
+		executor := cmd.Executor
+		task.Executor = &executor
+	} else {
+		task.Command = prepareCommand(cmd)
+	}
+
+	if len(cmd.Labels) > 0 {
+		task.Labels = &mesosproto.Labels{Labels: cmd.Labels}
+	}
+
+	if cmd.Discovery.Name != nil {
+		discovery := cmd.Discovery
+		task.Discovery = &discovery
+	}
+
+	return task
+}
+
+// PrepareTaskInfo build the TaskInfo with the default client
+func PrepareTaskInfo(cmd Command, offer mesosproto.Offer) (mesosproto.TaskInfo, error) {
+	return defaultClient.PrepareTaskInfo(cmd, offer)
+}
+
+// taskResources return the resources the command need
+func taskResources(cmd Command) []mesosproto.Resource {
+	resources := []mesosproto.Resource{
+		scalarResource("cpus", cmd.CPU),
+		scalarResource("mem", cmd.Memory),
+	}
+	if cmd.Disk > 0 {
+		resources = append(resources, scalarResource("disk", cmd.Disk))
+	}
+	if cmd.Gpus > 0 {
+		resources = append(resources, scalarResource("gpus", cmd.Gpus))
+	}
+
+	names := make([]string, 0, len(cmd.ScalarResources))
+	for name := range cmd.ScalarResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cmd.ScalarResources[name] > 0 {
+			resources = append(resources, scalarResource(name, cmd.ScalarResources[name]))
+		}
+	}
+
+	var ports []mesosproto.Value_Range
+	seen := make(map[uint32]bool)
+	for _, port := range cmd.DockerPortMappings {
+		if port.HostPort == 0 || seen[port.HostPort] {
+			continue
+		}
+		seen[port.HostPort] = true
+		ports = append(ports, mesosproto.Value_Range{Begin: uint64(port.HostPort), End: uint64(port.HostPort)})
+	}
+	if len(ports) > 0 {
+		resources = append(resources, rangesResource("ports", ports))
+	}
+
+	return resources
+}
+
+// scalarResource create a scalar resource
+func scalarResource(name string, value float64) mesosproto.Resource {
+	return mesosproto.Resource{
+		Name:   name,
+		Type:   mesosproto.SCALAR.Enum(),
+		Scalar: &mesosproto.Value_Scalar{Value: value},
+	}
+}
+
+// rangesResource create a ranges resource
+func rangesResource(name string, ranges []mesosproto.Value_Range) mesosproto.Resource {
+	return mesosproto.Resource{
+		Name:   name,
+		Type:   mesosproto.RANGES.Enum(),
+		Ranges: &mesosproto.Value_Ranges{Range: ranges},
+	}
+}
+
+// prepareCommand build the CommandInfo of the command
+func prepareCommand(cmd Command) *mesosproto.CommandInfo {
+	command := &mesosproto.CommandInfo{
+		Shell:     proto.Bool(cmd.Shell),
+		URIs:      cmd.Uris,
+		Arguments: cmd.Arguments,
+	}
+	if cmd.Command != "" {
+		command.Value = proto.String(cmd.Command)
+	}
+	if len(cmd.Environment.Variables) > 0 {
+		environment := cmd.Environment
+		command.Environment = &environment
+	}
+	return command
+}
+
+// prepareContainer build the ContainerInfo of the command
+func (c *Client) prepareContainer(cmd Command) *mesosproto.ContainerInfo {
+	container := &mesosproto.ContainerInfo{
+		Volumes:      cmd.Volumes,
+		NetworkInfos: cmd.NetworkInfo,
+	}
+
+	networkMode := strings.ToLower(cmd.NetworkMode)
+	if cmd.Hostname != "" && networkMode != "host" {
+		container.Hostname = proto.String(cmd.Hostname)
+	}
+	if cmd.LinuxInfo.Size() > 0 {
+		linuxInfo := cmd.LinuxInfo
+		container.LinuxInfo = &linuxInfo
+	}
+
+	if strings.ToLower(cmd.ContainerType) == "docker" {
+		container.Type = mesosproto.ContainerInfo_DOCKER.Enum()
+		container.Docker = &mesosproto.ContainerInfo_DockerInfo{
+			Image:          cmd.ContainerImage,
+			PortMappings:   cmd.DockerPortMappings,
+			Privileged:     proto.Bool(cmd.Privileged),
+			Parameters:     cmd.DockerParameter,
+			ForcePullImage: proto.Bool(strings.ToLower(cmd.PullPolicy) == "always"),
+		}
+		if network, ok := dockerNetworks[networkMode]; ok {
+			container.Docker.Network = network.Enum()
+		}
+		return container
+	}
+
+	container.Type = mesosproto.ContainerInfo_MESOS.Enum()
+	if cmd.ContainerImage != "" {
+		container.Mesos = &mesosproto.ContainerInfo_MesosInfo{
+			Image: &mesosproto.Image{
+				Type:   mesosproto.Image_DOCKER.Enum(),
+				Docker: &mesosproto.Image_Docker{Name: cmd.ContainerImage},
+				Cached: proto.Bool(strings.ToLower(cmd.PullPolicy) != "always"),
+			},
+		}
+	}
+
+	// the mesos containerizer need a cni network for port mappings
+	if len(container.NetworkInfos) == 0 && (networkMode == "bridge" || networkMode == "user") && c.config.MesosCNI != "" {
+		container.NetworkInfos = []mesosproto.NetworkInfo{{Name: proto.String(c.config.MesosCNI)}}
+	}
+	if len(container.NetworkInfos) > 0 && len(cmd.DockerPortMappings) > 0 {
+		var portMappings []mesosproto.NetworkInfo_PortMapping
+		for _, port := range cmd.DockerPortMappings {
+			portMappings = append(portMappings, mesosproto.NetworkInfo_PortMapping{
+				HostPort:      port.HostPort,
+				ContainerPort: port.ContainerPort,
+				Protocol:      port.Protocol,
+			})
+		}
+		networkInfos := make([]mesosproto.NetworkInfo, len(container.NetworkInfos))
+		copy(networkInfos, container.NetworkInfos)
+		networkInfos[0].PortMappings = portMappings
+		container.NetworkInfos = networkInfos
+	}
+
+	return container
+}
+
+// LaunchOperation create the operation to launch the tasks
+func LaunchOperation(tasks ...mesosproto.TaskInfo) mesosproto.Offer_Operation {
+	return mesosproto.Offer_Operation{
+		Type: mesosproto.Offer_Operation_LAUNCH,
+		Launch: &mesosproto.Offer_Operation_Launch{
+			TaskInfos: tasks,
+		},
+	}
+}
+
+// AcceptOffer will accept the given offers with the operations
+func AcceptOffer(offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) *mesosproto.Call {
+	accept := &mesosproto.Call{
+		Type: mesosproto.Call_ACCEPT,
+		Accept: &mesosproto.Call_Accept{
+			OfferIDs:   offerIds,
+			Operations: operations,
+			Filters:    filters,
+		},
+	}
+	return accept
+}
+
+// Accept the offers and run the operations on them
+func (c *Client) Accept(offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) error {
+	return c.AcceptContext(context.Background(), offerIds, operations, filters)
+}
+
+// AcceptContext accept the offers until the context is done. The operations
+// are tracked if the client has an operation tracker, but without the agent
+// they can not be reconciled until mesos sent a status update. Use
+// AcceptAgentContext if the agent of the offers is known.
+func (c *Client) AcceptContext(ctx context.Context, offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) error {
+	return c.accept(ctx, nil, offerIds, operations, filters)
+}
+
+// AcceptAgent accept the offers of the agent and run the operations on them.
+// The agent is remembered for the tracked operations.
+func (c *Client) AcceptAgent(agentID mesosproto.AgentID, offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) error {
+	return c.AcceptAgentContext(context.Background(), agentID, offerIds, operations, filters)
+}
+
+// AcceptAgentContext accept the offers of the agent until the context is done
+func (c *Client) AcceptAgentContext(ctx context.Context, agentID mesosproto.AgentID, offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) error {
+	return c.accept(ctx, &agentID, offerIds, operations, filters)
+}
+
+// accept the offers and track the operations with the agent, if the client
+// has an operation tracker
+func (c *Client) accept(ctx context.Context, agentID *mesosproto.AgentID, offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) error {
+	if c.Operations == nil {
+		return c.CallContext(ctx, AcceptOffer(offerIds, operations, filters))
+	}
+
+	operations = c.Operations.assign(operations)
+	err := c.CallContext(ctx, AcceptOffer(offerIds, operations, filters))
+	if err == nil {
+		c.Operations.track(operations, agentID)
+	}
+	return err
+}
+
+// LaunchTask launch the command on the offer and return the TaskInfo of it
+func (c *Client) LaunchTask(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	return c.LaunchTaskContext(context.Background(), offer, cmd, filters)
+}
+
+// LaunchTaskContext launch the command on the offer until the context is done
+func (c *Client) LaunchTaskContext(ctx context.Context, offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	task, err := c.PrepareTaskInfo(cmd, offer)
+	if err != nil {
+		logrus.WithField("func", "LaunchTask").Error("Task ", cmd.TaskName, " does not fit on ", offer.GetHostname())
+		return task, err
+	}
+	logrus.WithField("func", "LaunchTask").Debug("Launch task ", task.TaskID.Value, " on ", offer.GetHostname())
+
+	err = c.AcceptAgentContext(ctx, offer.AgentID, []mesosproto.OfferID{offer.ID}, []mesosproto.Offer_Operation{LaunchOperation(task)}, filters)
+	return task, err
+}
+
+// Accept the offers with the default client
+func Accept(offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) error {
+	return defaultClient.Accept(offerIds, operations, filters)
+}
+
+// AcceptAgent accept the offers of the agent with the default client
+func AcceptAgent(agentID mesosproto.AgentID, offerIds []mesosproto.OfferID, operations []mesosproto.Offer_Operation, filters *mesosproto.Filters) error {
+	return defaultClient.AcceptAgent(agentID, offerIds, operations, filters)
+}
+
+// LaunchTask launch the command with the default client
+func LaunchTask(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	return defaultClient.LaunchTask(offer, cmd, filters)
+}
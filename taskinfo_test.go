@@ -0,0 +1,116 @@
+package mesosutil
+
+import (
+	"errors"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+func TestPrepareTaskInfo(t *testing.T) {
+	c := NewClient(&FrameworkConfig{FrameworkRole: "web", MesosCNI: "cni"})
+	offer := testOffer("1", "agent1", 4, 4096, mesosproto.Value_Range{Begin: 31000, End: 31010})
+
+	task, err := c.PrepareTaskInfo(Command{
+		TaskName:       "web",
+		TaskID:         "web.1",
+		ContainerImage: "nginx",
+		NetworkMode:    "bridge",
+		CPU:            1,
+		Memory:         128,
+		DockerPortMappings: []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			{HostPort: 0, ContainerPort: 80, Protocol: proto.String("tcp")},
+		},
+	}, offer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if task.TaskID.Value != "web.1" || task.AgentID.Value != offer.AgentID.Value {
+		t.Errorf("got task %s on agent %s", task.TaskID.Value, task.AgentID.Value)
+	}
+	if task.Container.GetType() != mesosproto.ContainerInfo_MESOS || task.Container.Mesos.Image.Docker.Name != "nginx" {
+		t.Errorf("got container %v, want the mesos containerizer with image nginx", task.Container)
+	}
+	if scalarValue(task.Resources, "cpus") != 1 || scalarValue(task.Resources, "mem") != 128 {
+		t.Errorf("got resources %v", task.Resources)
+	}
+	mappings := task.Container.NetworkInfos[0].PortMappings
+	if len(mappings) != 1 || mappings[0].HostPort != 31000 {
+		t.Errorf("got port mappings %v, want host port 31000", mappings)
+	}
+}
+
+func TestPrepareTaskInfoNotEnoughResources(t *testing.T) {
+	c := NewClient(&FrameworkConfig{FrameworkRole: "web"})
+	reserved := scalarResource("cpus", 8)
+	reserved.Reservations = []mesosproto.Resource_ReservationInfo{{
+		Type: mesosproto.Resource_ReservationInfo_STATIC.Enum(),
+		Role: proto.String("db"),
+	}}
+	offer := testOffer("1", "agent1", 1, 4096, mesosproto.Value_Range{Begin: 31000, End: 31000})
+	offer.Resources = append(offer.Resources, reserved)
+
+	tests := []struct {
+		name string
+		cmd  Command
+	}{
+		{"cpus of an other role", Command{TaskName: "a", CPU: 2, Memory: 128}},
+		{"port outside the offer", Command{TaskName: "b", CPU: 1, Memory: 128, DockerPortMappings: []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			{HostPort: 8080, ContainerPort: 80},
+		}}},
+		{"no free port", Command{TaskName: "c", CPU: 1, Memory: 128, DockerPortMappings: []mesosproto.ContainerInfo_DockerInfo_PortMapping{
+			{HostPort: 0, ContainerPort: 80},
+			{HostPort: 0, ContainerPort: 443},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.PrepareTaskInfo(tt.cmd, offer); !errors.Is(err, ErrNoResources) {
+				t.Errorf("PrepareTaskInfo() = %v, want ErrNoResources", err)
+			}
+		})
+	}
+}
+
+func TestLaunchTaskDoesNotSendUnfitTask(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+	offer := testOffer("1", "agent1", 1, 256)
+
+	if _, err := c.LaunchTask(offer, Command{TaskName: "big", CPU: 4, Memory: 128}, nil); !errors.Is(err, ErrNoResources) {
+		t.Errorf("LaunchTask() = %v, want ErrNoResources", err)
+	}
+	if got := len(master.received(mesosproto.Call_ACCEPT)); got != 0 {
+		t.Errorf("got %d ACCEPT calls, want none", got)
+	}
+}
+
+func TestIsRessourceMatchedRole(t *testing.T) {
+	previous := defaultClient
+	defer func() { defaultClient = previous }()
+
+	reserved := scalarResource("cpus", 4)
+	reserved.Reservations = []mesosproto.Resource_ReservationInfo{{
+		Type: mesosproto.Resource_ReservationInfo_STATIC.Enum(),
+		Role: proto.String("db"),
+	}}
+	resources := []mesosproto.Resource{reserved, scalarResource("mem", 1024)}
+	cmd := Command{TaskName: "web", CPU: 1, Memory: 128}
+
+	if err := SetConfig(&FrameworkConfig{FrameworkRole: "web"}); err != nil {
+		t.Fatal(err)
+	}
+	if IsRessourceMatched(resources, cmd) {
+		t.Error("IsRessourceMatched() = true for cpus reserved for an other role")
+	}
+
+	if err := SetConfig(&FrameworkConfig{FrameworkRole: "db"}); err != nil {
+		t.Fatal(err)
+	}
+	if !IsRessourceMatched(resources, cmd) {
+		t.Error("IsRessourceMatched() = false for cpus reserved for the role")
+	}
+}
@@ -0,0 +1,57 @@
+package mesosutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsVersions map the MesosTLSMinVersion config to the tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig create the tls config to talk with the mesos master. The
+// certificate of the master is always verified, except MesosInsecure is
+// set explicitly.
+func TLSConfig(cfg *FrameworkConfig) (*tls.Config, error) {
+	// #nosec G402
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.MesosInsecure,
+		ServerName:         cfg.MesosServerName,
+	}
+
+	if cfg.MesosTLSMinVersion != "" {
+		version, ok := tlsVersions[cfg.MesosTLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown tls version %q", cfg.MesosTLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if cfg.MesosCAFile != "" {
+		pem, err := os.ReadFile(cfg.MesosCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read ca bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca bundle %s", cfg.MesosCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.MesosCertFile != "" || cfg.MesosKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.MesosCertFile, cfg.MesosKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
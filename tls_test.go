@@ -0,0 +1,72 @@
+package mesosutil
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+func TestNewClientEInvalidTLS(t *testing.T) {
+	dir := t.TempDir()
+	noCerts := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(noCerts, []byte("no certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  FrameworkConfig
+	}{
+		{"unknown tls version", FrameworkConfig{MesosTLSMinVersion: "1.4"}},
+		{"missing ca bundle", FrameworkConfig{MesosCAFile: filepath.Join(dir, "missing.pem")}},
+		{"ca bundle without certificates", FrameworkConfig{MesosCAFile: noCerts}},
+		{"missing client key", FrameworkConfig{MesosCertFile: noCerts}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			c, err := NewClientE(&cfg)
+			if err == nil {
+				t.Fatal("NewClientE accepted an invalid tls config")
+			}
+			if callErr := c.Call(&mesosproto.Call{Type: mesosproto.Call_REVIVE}); callErr != err {
+				t.Errorf("Call() = %v, want the tls error %v", callErr, err)
+			}
+			if setErr := SetConfig(&cfg); setErr == nil {
+				t.Error("SetConfig accepted an invalid tls config")
+			}
+		})
+	}
+}
+
+func TestTLSConfigVerifiesByDefault(t *testing.T) {
+	cfg, err := TLSConfig(&FrameworkConfig{MesosTLSMinVersion: "1.2", MesosServerName: "master.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.InsecureSkipVerify {
+		t.Error("certificates are not verified without MesosInsecure")
+	}
+	if cfg.MinVersion != tls.VersionTLS12 || cfg.ServerName != "master.example.com" {
+		t.Errorf("got min version %x and server name %q", cfg.MinVersion, cfg.ServerName)
+	}
+
+	cfg, err = TLSConfig(&FrameworkConfig{MesosInsecure: true})
+	if err != nil || !cfg.InsecureSkipVerify {
+		t.Error("MesosInsecure does not skip the verification")
+	}
+}
+
+func TestNewClientEValid(t *testing.T) {
+	c, err := NewClientE(&FrameworkConfig{MesosMasterServer: "localhost:5050"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(c.url("/"), "http://localhost:5050") {
+		t.Errorf("url() = %q", c.url("/"))
+	}
+}
@@ -0,0 +1,45 @@
+package mesosutil
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// MaxIdleConnsPerHost is how many idle keep-alive connections a client
+// hold to every mesos master
+var MaxIdleConnsPerHost = 16
+
+// MaxConnsPerHost limit the connections of a client to every mesos master.
+// The subscription stream use one of them the whole time.
+var MaxConnsPerHost = 64
+
+// newTransport create the pooled http transport of a client. All requests
+// of the client share its keep-alive connections.
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		MaxIdleConns:          MaxIdleConnsPerHost * 4,
+		MaxIdleConnsPerHost:   MaxIdleConnsPerHost,
+		MaxConnsPerHost:       MaxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// closeBody read the rest of the response body and close it, so the
+// connection can be used again
+func closeBody(res *http.Response) {
+	// #nosec G104
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxErrorBody))
+	res.Body.Close()
+}
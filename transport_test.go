@@ -0,0 +1,86 @@
+package mesosutil
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+)
+
+// newCountingMaster start a master which accept every call and count the
+// opened connections
+func newCountingMaster(t testing.TB) (*httptest.Server, *int32) {
+	var conns int32
+	master := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	master.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	master.Start()
+	t.Cleanup(master.Close)
+	return master, &conns
+}
+
+func TestCallsReuseConnections(t *testing.T) {
+	master, conns := newCountingMaster(t)
+	c := NewClient(&FrameworkConfig{MesosMasterServer: strings.TrimPrefix(master.URL, "http://")})
+
+	for i := 0; i < 100; i++ {
+		if err := c.Call(&mesosproto.Call{Type: mesosproto.Call_REVIVE}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := atomic.LoadInt32(conns); got != 1 {
+		t.Errorf("100 sequential calls opened %d connections, want 1", got)
+	}
+}
+
+func TestConcurrentCallsLimitConnections(t *testing.T) {
+	master, conns := newCountingMaster(t)
+	c := NewClient(&FrameworkConfig{MesosMasterServer: strings.TrimPrefix(master.URL, "http://")})
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if err := c.Call(&mesosproto.Call{Type: mesosproto.Call_REVIVE}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(conns); got > 8 {
+		t.Errorf("8 workers opened %d connections, want at most 8", got)
+	}
+}
+
+func BenchmarkClientCall(b *testing.B) {
+	master, conns := newCountingMaster(b)
+	c := NewClient(&FrameworkConfig{MesosMasterServer: strings.TrimPrefix(master.URL, "http://")})
+	call := &mesosproto.Call{Type: mesosproto.Call_REVIVE}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.Call(call); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "calls/s")
+	b.ReportMetric(float64(atomic.LoadInt32(conns)), "conns")
+}
@@ -22,7 +22,14 @@ type FrameworkConfig struct {
 	Username              string
 	Password              string
 	MesosMasterServer     string
+	MesosMasterServers    []string
 	MesosSSL              bool
+	MesosCAFile           string
+	MesosCertFile         string
+	MesosKeyFile          string
+	MesosServerName       string
+	MesosTLSMinVersion    string
+	MesosInsecure         bool
 	MesosStreamID         string
 	MesosCNI              string
 	TaskID                string
@@ -56,7 +63,13 @@ type Command struct {
 	Memory             float64
 	CPU                float64
 	Disk               float64
+	Gpus               float64
+	ScalarResources    map[string]float64
+	PersistentVolumes  []PersistentVolume
+	ReservationID      string
 	Agent              string
+	Constraints        []Constraint
+	AgentAttributes    map[string]string
 	Labels             []mesosproto.Label
 	State              string
 	StateTime          time.Time
@@ -80,14 +93,13 @@ type MesosAgent struct {
 
 // MesosSlaves ..
 type MesosSlaves struct {
-	ID         string `json:"id"`
-	Hostname   string `json:"hostname"`
-	Port       int    `json:"port"`
-	Attributes struct {
-	} `json:"attributes"`
-	Pid              string  `json:"pid"`
-	RegisteredTime   float64 `json:"registered_time"`
-	ReregisteredTime float64 `json:"reregistered_time"`
+	ID               string                 `json:"id"`
+	Hostname         string                 `json:"hostname"`
+	Port             int                    `json:"port"`
+	Attributes       map[string]interface{} `json:"attributes"`
+	Pid              string                 `json:"pid"`
+	RegisteredTime   float64                `json:"registered_time"`
+	ReregisteredTime float64                `json:"reregistered_time"`
 	Resources        struct {
 		Disk  float64 `json:"disk"`
 		Mem   float64 `json:"mem"`
@@ -0,0 +1,188 @@
+package mesosutil
+
+import (
+	"context"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// PersistentVolume is a mesos persistent volume of a command. The volume is
+// created out of reserved disk and keep its data if the task restart on the
+// same agent.
+type PersistentVolume struct {
+	// ID of the volume, unique per role on every agent
+	ID string `json:"id"`
+	// ContainerPath the volume is mounted at, relative to the sandbox
+	ContainerPath string `json:"container_path"`
+	// Size of the volume in MB
+	Size     float64 `json:"size"`
+	ReadOnly bool    `json:"read_only,omitempty"`
+}
+
+// persistenceID return the id of the persistent volume, or an empty string
+// if the resource is not a persistent volume
+func persistenceID(res mesosproto.Resource) string {
+	if res.Disk == nil || res.Disk.Persistence == nil {
+		return ""
+	}
+	return res.Disk.Persistence.GetID()
+}
+
+// FindVolumes return the persistent volumes of the resources with the ids
+func FindVolumes(resources []mesosproto.Resource, ids ...string) []mesosproto.Resource {
+	wanted := make(map[string]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var volumes []mesosproto.Resource
+	for _, res := range resources {
+		if id := persistenceID(res); id != "" && wanted[id] {
+			volumes = append(volumes, res)
+		}
+	}
+	return volumes
+}
+
+// CreateOperation create the operation to create the persistent volumes
+func CreateOperation(volumes ...mesosproto.Resource) mesosproto.Offer_Operation {
+	return mesosproto.Offer_Operation{
+		Type: mesosproto.Offer_Operation_CREATE,
+		Create: &mesosproto.Offer_Operation_Create{
+			Volumes: volumes,
+		},
+	}
+}
+
+// DestroyOperation create the operation to destroy the persistent volumes
+func DestroyOperation(volumes ...mesosproto.Resource) mesosproto.Offer_Operation {
+	return mesosproto.Offer_Operation{
+		Type: mesosproto.Offer_Operation_DESTROY,
+		Destroy: &mesosproto.Offer_Operation_Destroy{
+			Volumes: volumes,
+		},
+	}
+}
+
+// volumeResource take the disk of the volume out of one reserved disk
+// resource of the role. Disk reserved for the command is preferred.
+func (c *Client) volumeResource(remaining []mesosproto.Resource, cmd Command, volume PersistentVolume) (mesosproto.Resource, bool) {
+	role := c.config.FrameworkRole
+	selected := -1
+	for n, res := range remaining {
+		if res.GetName() != "disk" || res.Scalar == nil || res.Scalar.GetValue() < volume.Size {
+			continue
+		}
+		if persistenceID(res) != "" || ReservationRole(res) == "" || !usableResource(res, role) {
+			continue
+		}
+		id := reservationLabel(res)
+		if id == ReservationID(cmd) {
+			selected = n
+			break
+		}
+		if id == "" && selected < 0 {
+			selected = n
+		}
+	}
+	if selected < 0 {
+		return mesosproto.Resource{}, false
+	}
+
+	mode := mesosproto.RW
+	if volume.ReadOnly {
+		mode = mesosproto.RO
+	}
+	persistence := &mesosproto.Resource_DiskInfo_Persistence{ID: volume.ID}
+	if principal := c.config.FrameworkInfo.GetPrincipal(); principal != "" {
+		persistence.Principal = proto.String(principal)
+	}
+
+	res := resourceMeta(remaining[selected])
+	res.Scalar = &mesosproto.Value_Scalar{Value: volume.Size}
+	res.Disk = &mesosproto.Resource_DiskInfo{
+		Persistence: persistence,
+		Volume: &mesosproto.Volume{
+			ContainerPath: volume.ContainerPath,
+			Mode:          mode.Enum(),
+		},
+	}
+	if remaining[selected].Disk != nil {
+		res.Disk.Source = remaining[selected].Disk.Source
+	}
+	remaining[selected].Scalar.Value -= volume.Size
+	return res, true
+}
+
+// LaunchPersistent launch the command with its persistent volumes. Volumes
+// which already exist on the agent of the offer are reused, the missing
+// ones are created out of reserved disk with the same ACCEPT call.
+func (c *Client) LaunchPersistent(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	return c.LaunchPersistentContext(context.Background(), offer, cmd, filters)
+}
+
+// LaunchPersistentContext launch the command with its persistent volumes until the context is done
+func (c *Client) LaunchPersistentContext(ctx context.Context, offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	remaining := copyResources(offer.Resources)
+
+	var volumes, created []mesosproto.Resource
+	for _, volume := range cmd.PersistentVolumes {
+		if existing := FindVolumes(offer.Resources, volume.ID); len(existing) > 0 {
+			logrus.WithField("func", "LaunchPersistent").Debug("Reuse volume ", volume.ID, " on ", offer.GetHostname())
+			volumes = append(volumes, existing[0])
+			continue
+		}
+		res, ok := c.volumeResource(remaining, cmd, volume)
+		if !ok {
+			return mesosproto.TaskInfo{}, ErrNoResources
+		}
+		logrus.WithField("func", "LaunchPersistent").Debug("Create volume ", volume.ID, " on ", offer.GetHostname())
+		volumes = append(volumes, res)
+		created = append(created, res)
+	}
+
+	cmd, resources, ok := allocateResources(remaining, cmd, c.config.FrameworkRole)
+	if !ok {
+		return mesosproto.TaskInfo{}, ErrNoResources
+	}
+	task := c.prepareTaskInfo(cmd, offer, append(resources, volumes...))
+
+	var operations []mesosproto.Offer_Operation
+	if len(created) > 0 {
+		operations = append(operations, CreateOperation(created...))
+	}
+	operations = append(operations, LaunchOperation(task))
+
+	err := c.AcceptAgentContext(ctx, offer.AgentID, []mesosproto.OfferID{offer.ID}, operations, filters)
+	return task, err
+}
+
+// DestroyVolumes destroy the persistent volumes with the ids on the agent
+// of the offer. The data of the volumes is removed, the disk stay reserved.
+func (c *Client) DestroyVolumes(offer mesosproto.Offer, filters *mesosproto.Filters, ids ...string) error {
+	return c.DestroyVolumesContext(context.Background(), offer, filters, ids...)
+}
+
+// DestroyVolumesContext destroy the persistent volumes until the context is done
+func (c *Client) DestroyVolumesContext(ctx context.Context, offer mesosproto.Offer, filters *mesosproto.Filters, ids ...string) error {
+	volumes := FindVolumes(offer.Resources, ids...)
+	if len(volumes) == 0 {
+		return ErrNoResources
+	}
+
+	logrus.WithField("func", "DestroyVolumes").Debug("Destroy ", len(volumes), " volumes on ", offer.GetHostname())
+	return c.AcceptAgentContext(ctx, offer.AgentID, []mesosproto.OfferID{offer.ID}, []mesosproto.Offer_Operation{DestroyOperation(volumes...)}, filters)
+}
+
+// LaunchPersistent launch the command with its volumes with the default client
+func LaunchPersistent(offer mesosproto.Offer, cmd Command, filters *mesosproto.Filters) (mesosproto.TaskInfo, error) {
+	return defaultClient.LaunchPersistent(offer, cmd, filters)
+}
+
+// DestroyVolumes destroy the persistent volumes with the default client
+func DestroyVolumes(offer mesosproto.Offer, filters *mesosproto.Filters, ids ...string) error {
+	return defaultClient.DestroyVolumes(offer, filters, ids...)
+}
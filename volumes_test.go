@@ -0,0 +1,155 @@
+package mesosutil
+
+import (
+	"errors"
+	"testing"
+
+	mesosproto "github.com/AVENTER-UG/mesos-util/proto"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// reservedDisk create disk reserved for the role, labelled with the
+// reservation id if it is not empty
+func reservedDisk(size float64, role string, id string) mesosproto.Resource {
+	disk := scalarResource("disk", size)
+	reservation := mesosproto.Resource_ReservationInfo{
+		Type: mesosproto.Resource_ReservationInfo_DYNAMIC.Enum(),
+		Role: proto.String(role),
+	}
+	if id != "" {
+		reservation.Labels = &mesosproto.Labels{Labels: []mesosproto.Label{
+			{Key: ReservationLabel, Value: proto.String(id)},
+		}}
+	}
+	disk.Reservations = []mesosproto.Resource_ReservationInfo{reservation}
+	return disk
+}
+
+// persistentVolume create an existing persistent volume out of reserved disk
+func persistentVolume(id string, size float64, role string) mesosproto.Resource {
+	volume := reservedDisk(size, role, "db")
+	volume.Disk = &mesosproto.Resource_DiskInfo{
+		Persistence: &mesosproto.Resource_DiskInfo_Persistence{ID: id},
+		Volume:      &mesosproto.Volume{ContainerPath: "data", Mode: mesosproto.RW.Enum()},
+	}
+	return volume
+}
+
+// volumeCommand return a command with the persistent volume data
+func volumeCommand() Command {
+	return Command{
+		TaskName:          "db",
+		TaskID:            "db.1",
+		CPU:               1,
+		Memory:            128,
+		PersistentVolumes: []PersistentVolume{{ID: "data", ContainerPath: "data", Size: 512}},
+	}
+}
+
+func TestLaunchPersistentCreateVolume(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+
+	offer := testOffer("1", "agent1", 4, 4096)
+	offer.Resources = append(offer.Resources, reservedDisk(2048, "web", ""), reservedDisk(1024, "web", "db"))
+
+	task, err := c.LaunchPersistent(offer, volumeCommand(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accept := master.received(mesosproto.Call_ACCEPT)
+	if len(accept) != 1 {
+		t.Fatalf("got %d ACCEPT calls, want 1", len(accept))
+	}
+	operations := accept[0].Accept.Operations
+	if len(operations) != 2 || operations[0].Type != mesosproto.Offer_Operation_CREATE || operations[1].Type != mesosproto.Offer_Operation_LAUNCH {
+		t.Fatalf("got operations %v, want CREATE and LAUNCH", operations)
+	}
+
+	volumes := operations[0].Create.Volumes
+	if len(volumes) != 1 || persistenceID(volumes[0]) != "data" || volumes[0].Scalar.GetValue() != 512 {
+		t.Fatalf("got volumes %v, want the volume data of 512 MB", volumes)
+	}
+	// the disk reserved for the command is used before the unlabelled disk
+	if reservationLabel(volumes[0]) != "db" {
+		t.Errorf("volume created out of disk with reservation %q, want db", reservationLabel(volumes[0]))
+	}
+	if volumes[0].Disk.Volume.ContainerPath != "data" {
+		t.Errorf("got container path %q, want data", volumes[0].Disk.Volume.ContainerPath)
+	}
+	if len(FindVolumes(task.Resources, "data")) != 1 {
+		t.Errorf("task resources %v do not contain the volume", task.Resources)
+	}
+}
+
+func TestLaunchPersistentReuseVolume(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+
+	offer := testOffer("1", "agent1", 4, 4096)
+	offer.Resources = append(offer.Resources, persistentVolume("data", 512, "web"), reservedDisk(1024, "web", "db"))
+
+	task, err := c.LaunchPersistent(offer, volumeCommand(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accept := master.received(mesosproto.Call_ACCEPT)
+	if len(accept) != 1 {
+		t.Fatalf("got %d ACCEPT calls, want 1", len(accept))
+	}
+	operations := accept[0].Accept.Operations
+	if len(operations) != 1 || operations[0].Type != mesosproto.Offer_Operation_LAUNCH {
+		t.Fatalf("got operations %v, want only LAUNCH", operations)
+	}
+	if len(FindVolumes(task.Resources, "data")) != 1 {
+		t.Errorf("task resources %v do not contain the existing volume", task.Resources)
+	}
+}
+
+func TestLaunchPersistentWithoutReservedDisk(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+
+	offer := testOffer("1", "agent1", 4, 4096)
+	offer.Resources = append(offer.Resources, scalarResource("disk", 4096), reservedDisk(1024, "other", ""))
+
+	if _, err := c.LaunchPersistent(offer, volumeCommand(), nil); !errors.Is(err, ErrNoResources) {
+		t.Errorf("LaunchPersistent() = %v, want ErrNoResources", err)
+	}
+	if got := len(master.received(mesosproto.Call_ACCEPT)); got != 0 {
+		t.Errorf("got %d ACCEPT calls, want none", got)
+	}
+}
+
+func TestDestroyVolumes(t *testing.T) {
+	master := newFakeMaster(t)
+	c := NewClient(master.config())
+
+	offer := testOffer("1", "agent1", 4, 4096)
+	offer.Resources = append(offer.Resources, persistentVolume("data", 512, "web"), persistentVolume("logs", 128, "web"))
+
+	if err := c.DestroyVolumes(offer, nil, "missing"); !errors.Is(err, ErrNoResources) {
+		t.Errorf("DestroyVolumes() of an unknown volume = %v, want ErrNoResources", err)
+	}
+	if got := len(master.received(mesosproto.Call_ACCEPT)); got != 0 {
+		t.Fatalf("got %d ACCEPT calls for an unknown volume, want none", got)
+	}
+
+	if err := c.DestroyVolumes(offer, nil, "data"); err != nil {
+		t.Fatal(err)
+	}
+	accept := master.received(mesosproto.Call_ACCEPT)
+	if len(accept) != 1 {
+		t.Fatalf("got %d ACCEPT calls, want 1", len(accept))
+	}
+	operations := accept[0].Accept.Operations
+	if len(operations) != 1 || operations[0].Type != mesosproto.Offer_Operation_DESTROY {
+		t.Fatalf("got operations %v, want DESTROY", operations)
+	}
+	if volumes := operations[0].Destroy.Volumes; len(volumes) != 1 || persistenceID(volumes[0]) != "data" {
+		t.Errorf("got destroyed volumes %v, want only data", volumes)
+	}
+}